@@ -0,0 +1,138 @@
+package flagutils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mandelsoft/goutils/set"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils/pflags"
+)
+
+// Args renders the state of an OptionSet back to command line arguments.
+// The OptionSet must contain a FlagSetRecorder.
+// See FlagArgs for the details.
+func Args(opts OptionSetProvider) ([]string, error) {
+	fs := GetFlagSet(opts)
+	if fs == nil {
+		return nil, fmt.Errorf("no flag set recorded for option set")
+	}
+	return FlagArgs(fs), nil
+}
+
+// FlagArgs renders the changed flags of a pflag.FlagSet (optionally
+// restricted to the given names) to command line arguments reproducing
+// the actual flag values. Alias flags and secret flags (see IsSecretFlag)
+// are omitted.
+// Map values are rendered as separate assignments, slice values (like
+// stringSlice) as comma separated (CSV) list and the elements of other
+// values implementing pflag.SliceValue, like stringArray, as separate flags.
+func FlagArgs(fs *pflag.FlagSet, names ...string) []string {
+	var filter set.Set[string]
+	if len(names) > 0 {
+		filter = set.New[string](names...)
+	}
+
+	var args []string
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || FlagAliasTarget(f) != nil || IsSecretFlag(f) {
+			return
+		}
+		if filter != nil && !filter.Has(f.Name) {
+			return
+		}
+		args = append(args, flagArgs(fs, f)...)
+	})
+	return args
+}
+
+func flagArgs(fs *pflag.FlagSet, f *pflag.Flag) []string {
+	var values []string
+
+	if list, ok := FlagAssignments(f); ok {
+		values = list
+	} else if v, ok := f.Value.(pflag.SliceValue); ok {
+		if strings.HasSuffix(f.Value.Type(), "Slice") {
+			values = []string{csvList(v.GetSlice())}
+		} else {
+			values = v.GetSlice()
+		}
+	} else {
+		s := f.Value.String()
+		if f.NoOptDefVal != "" && s == f.NoOptDefVal {
+			return []string{"--" + f.Name}
+		}
+		values = []string{s}
+	}
+
+	var args []string
+	for _, v := range values {
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, v))
+	}
+	return args
+}
+
+// FlagAssignments provides the value of a map flag as list of
+// assignments <key>=<value>, which can be passed separately to
+// the Set method of the flag value. Like the values of the pflag
+// map types, they are quoted as CSV.
+// The pflags.MapValue types and the core pflag map types (stringToString,
+// stringToInt and stringToInt64) are supported. For other flags, false
+// is returned.
+func FlagAssignments(f *pflag.Flag) ([]string, bool) {
+	if m, ok := f.Value.(pflags.MapValue); ok {
+		return m.GetAssignments(), true
+	}
+
+	// the core map types provide the assignments as list
+	// in brackets, which is parsed like the pflag getters do.
+	var list []string
+	s := strings.TrimSuffix(strings.TrimPrefix(f.Value.String(), "["), "]")
+	switch f.Value.Type() {
+	case "stringToString":
+		if s != "" {
+			list, _ = csv.NewReader(strings.NewReader(s)).Read()
+		}
+	case "stringToInt", "stringToInt64":
+		if s != "" {
+			list = strings.Split(s, ",")
+		}
+	default:
+		return nil, false
+	}
+	slices.Sort(list)
+	for i, a := range list {
+		list[i] = csvList([]string{a})
+	}
+	return list, true
+}
+
+func csvList(list []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	//nolint: errcheck // writing to buffer
+	w.Write(list)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+var unquoted = regexp.MustCompile(`^[a-zA-Z0-9_@%+=:,./-]+$`)
+
+// QuoteArgs renders arguments to a command line string
+// usable for a POSIX shell.
+func QuoteArgs(args ...string) string {
+	list := make([]string, len(args))
+	for i, a := range args {
+		if unquoted.MatchString(a) {
+			list[i] = a
+		} else {
+			list[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(list, " ")
+}
@@ -0,0 +1,98 @@
+package flagutils_test
+
+import (
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/pflags"
+)
+
+var _ = Describe("argument rendering", func() {
+	var fs *pflag.FlagSet
+	var labels map[string]string
+
+	setup := func() *pflag.FlagSet {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.Bool("force", false, "force")
+		fs.Bool("check", true, "check")
+		fs.String("name", "", "name")
+		fs.StringSlice("columns", nil, "columns")
+		fs.StringArray("items", nil, "items")
+		fs.StringToString("env", nil, "env")
+		labels = nil
+		pflags.StringToStringVarP(fs, &labels, "labels", "", nil, "labels")
+		var secret []byte
+		pflags.SecretVarP(fs, &secret, "token", "", nil, "token")
+		return fs
+	}
+
+	BeforeEach(func() {
+		fs = setup()
+	})
+
+	It("renders changed flags", func() {
+		MustBeSuccessful(fs.Parse([]string{"--force", "--check=false", "--name", "a b"}))
+		Expect(flagutils.FlagArgs(fs)).To(Equal([]string{"--check=false", "--force", "--name=a b"}))
+	})
+
+	It("renders slice and map flags", func() {
+		MustBeSuccessful(fs.Parse([]string{
+			"--columns", "a,b", "--columns", "c d",
+			"--items", "x,y", "--items", "z",
+			"--env", "b=2", "--env", "a=1",
+			"--labels", "x=a,b",
+		}))
+		args := flagutils.FlagArgs(fs)
+		Expect(args).To(Equal([]string{
+			"--columns=a,b,c d",
+			"--env=a=1", "--env=b=2",
+			"--items=x,y", "--items=z",
+			`--labels="x=a,b"`,
+		}))
+
+		other := setup()
+		MustBeSuccessful(other.Parse(args))
+		Expect(flagutils.FlagValue[[]string](other, "columns")).To(Equal([]string{"a", "b", "c d"}))
+		Expect(flagutils.FlagValue[[]string](other, "items")).To(Equal([]string{"x,y", "z"}))
+		Expect(flagutils.FlagValue[map[string]string](other, "env")).To(Equal(map[string]string{"a": "1", "b": "2"}))
+		Expect(labels).To(Equal(map[string]string{"x": "a,b"}))
+	})
+
+	It("renders core map flags with commas in values", func() {
+		MustBeSuccessful(fs.Parse([]string{"--env", `"a=x,y"`, "--env", "b=2"}))
+		args := flagutils.FlagArgs(fs)
+		Expect(args).To(Equal([]string{`--env="a=x,y"`, "--env=b=2"}))
+
+		other := setup()
+		MustBeSuccessful(other.Parse(args))
+		Expect(flagutils.FlagValue[map[string]string](other, "env")).To(Equal(map[string]string{"a": "x,y", "b": "2"}))
+	})
+
+	It("omits secret flags", func() {
+		MustBeSuccessful(fs.Parse([]string{"--token", "secret", "--name", "n"}))
+		Expect(flagutils.FlagArgs(fs)).To(Equal([]string{"--name=n"}))
+	})
+
+	It("restricts flags", func() {
+		MustBeSuccessful(fs.Parse([]string{"--force", "--name", "n"}))
+		Expect(flagutils.FlagArgs(fs, "name")).To(Equal([]string{"--name=n"}))
+	})
+
+	It("renders option sets", func() {
+		set := flagutils.NewOptionSet(flagutils.NewFlagSetRecorder(), parallel.New().WithAliases("workers"))
+		set.AddFlags(fs)
+		MustBeSuccessful(fs.Parse([]string{"--workers", "4"}))
+		Expect(flagutils.Args(set)).To(Equal([]string{"--parallel=4"}))
+
+		_, err := flagutils.Args(flagutils.NewOptionSet(parallel.New()))
+		Expect(err).To(MatchError("no flag set recorded for option set"))
+	})
+
+	It("quotes arguments", func() {
+		Expect(flagutils.QuoteArgs("cmd", "--name=a b", "--x=it's", "--y=a,b")).To(Equal(`cmd '--name=a b' '--x=it'\''s' --y=a,b`))
+	})
+})
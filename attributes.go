@@ -0,0 +1,182 @@
+package flagutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mandelsoft/goutils/maputils"
+	"github.com/spf13/pflag"
+)
+
+// FlagAttributes describes additional attributes of a flag.
+// They are applied by ApplyFlagAttributes after the flag has been
+// added to a pflag.FlagSet. This can be used to rename flags without
+// breaking existing scripts.
+type FlagAttributes struct {
+	// Aliases are old names of the flag. They are hidden and print
+	// a deprecation message on use.
+	Aliases []string
+	// Deprecated is a deprecation message printed on use and shown in help.
+	Deprecated string
+	// ShorthandDeprecated is a deprecation message for the shorthand.
+	ShorthandDeprecated string
+	// Hidden flags are omitted in help.
+	Hidden bool
+	// Required flags must be given (see CheckRequired).
+	Required bool
+	// Secret flags are never echoed by interactive input.
+	Secret bool
+	// Choices is the list of possible values offered by interactive input.
+	Choices []string
+}
+
+// ApplyFlagAttributes applies the given attributes to the flag with the given name.
+func ApplyFlagAttributes(fs *pflag.FlagSet, name string, attrs FlagAttributes) {
+	f := fs.Lookup(name)
+	if f == nil {
+		return
+	}
+	if attrs.Hidden {
+		f.Hidden = true
+	}
+	if attrs.Deprecated != "" {
+		// keep it visible to show the message in help.
+		f.Deprecated = attrs.Deprecated
+	}
+	if attrs.ShorthandDeprecated != "" && f.Shorthand != "" {
+		f.ShorthandDeprecated = attrs.ShorthandDeprecated
+	}
+	if attrs.Required {
+		annotateFlag(f, FlagRequiredAnnotation, "true")
+	}
+	if attrs.Secret {
+		annotateFlag(f, FlagSecretAnnotation, "true")
+	}
+	if len(attrs.Choices) > 0 {
+		SetFlagChoices(f, attrs.Choices...)
+	}
+	for _, a := range attrs.Aliases {
+		AddFlagAlias(fs, name, a)
+	}
+}
+
+func annotateFlag(f *pflag.Flag, key string, values ...string) {
+	if f.Annotations == nil {
+		f.Annotations = map[string][]string{}
+	}
+	f.Annotations[key] = values
+}
+
+// FlagAliasProvider is an optional interface for Options objects
+// registering aliases for their flags. It is used by Prepare to
+// detect alias collisions.
+type FlagAliasProvider interface {
+	// GetFlagAliases provides the aliases for the flag names
+	// registered by the object.
+	GetFlagAliases() map[string][]string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// aliasValue forwards the value handling of an alias flag
+// to the original flag.
+type aliasValue struct {
+	target *pflag.Flag
+}
+
+func (a *aliasValue) Set(v string) error {
+	if err := a.target.Value.Set(v); err != nil {
+		return err
+	}
+	a.target.Changed = true
+	return nil
+}
+
+func (a *aliasValue) String() string {
+	return a.target.Value.String()
+}
+
+func (a *aliasValue) Type() string {
+	return a.target.Value.Type()
+}
+
+// AddFlagAlias adds a hidden alias for the flag with the given name.
+// Using the alias prints a deprecation message and sets the original flag.
+func AddFlagAlias(fs *pflag.FlagSet, name, alias string) *pflag.Flag {
+	f := fs.Lookup(name)
+	if f == nil {
+		return nil
+	}
+	a := fs.VarPF(&aliasValue{f}, alias, "", f.Usage)
+	a.NoOptDefVal = f.NoOptDefVal
+	a.Hidden = true
+	a.Deprecated = aliasDeprecation(f)
+	return a
+}
+
+func aliasDeprecation(f *pflag.Flag) string {
+	return fmt.Sprintf("use --%s instead", f.Name)
+}
+
+// FlagAliasTarget provides the original flag for an alias flag.
+// For other flags nil is returned.
+func FlagAliasTarget(f *pflag.Flag) *pflag.Flag {
+	if a, ok := f.Value.(*aliasValue); ok {
+		return a.target
+	}
+	return nil
+}
+
+// checkFlagAliases checks the aliases declared by FlagAliasProvider objects
+// for collisions with other aliases or the flags of the providers.
+// Collisions with flags of other options are reported by Check.
+func checkFlagAliases(opts OptionSet) error {
+	var providers []FlagAliasProvider
+	var keys []string
+	visitInstances(opts, "", func(o Options, key string) {
+		if p, ok := o.(FlagAliasProvider); ok {
+			providers = append(providers, p)
+			keys = append(keys, key)
+		}
+	})
+	if len(providers) == 0 {
+		return nil
+	}
+
+	owners := map[string]string{}
+	for i, p := range providers {
+		for n := range p.GetFlagAliases() {
+			owners[PrefixedName(keys[i], n)] = describeAliasProvider(p)
+		}
+	}
+
+	var errs []string
+	aliases := map[string]string{}
+	for i, p := range providers {
+		desc := describeAliasProvider(p)
+		m := p.GetFlagAliases()
+		for _, n := range maputils.OrderedKeys(m) {
+			for _, a := range m[n] {
+				a = PrefixedName(keys[i], a)
+				if o, ok := owners[a]; ok {
+					errs = append(errs, fmt.Sprintf("alias --%s of %s collides with flag of %s", a, desc, o))
+				} else if o, ok := aliases[a]; ok {
+					errs = append(errs, fmt.Sprintf("alias --%s of %s collides with alias of %s", a, desc, o))
+				} else {
+					aliases[a] = desc
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("flag alias collisions: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func describeAliasProvider(p FlagAliasProvider) string {
+	if o, ok := p.(Options); ok {
+		return DescribeOptions(o)
+	}
+	return fmt.Sprintf("%T", p)
+}
@@ -0,0 +1,97 @@
+package flagutils_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/parallel"
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("flag attributes", func() {
+	var set flagutils.ExtendableOptionSet
+	var fs *pflag.FlagSet
+	var buf *bytes.Buffer
+
+	BeforeEach(func() {
+		set = flagutils.NewOptionSet()
+		fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		buf = &bytes.Buffer{}
+		fs.SetOutput(buf)
+	})
+
+	It("accepts aliases", func() {
+		set.Add(parallel.New().WithAliases("threads"))
+		MustBeSuccessful(flagutils.Prepare(context.Background(), set, nil))
+		set.AddFlags(fs)
+
+		Expect(fs.Lookup("threads").Hidden).To(BeTrue())
+		Expect(fs.FlagUsages()).NotTo(ContainSubstring("threads"))
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--threads", "3"}))
+		Expect(parallel.From(set).Value()).To(Equal(3))
+		Expect(fs.Changed("parallel")).To(BeTrue())
+		Expect(flagutils.FlagOrigin(fs.Lookup("parallel"))).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+		Expect(buf.String()).To(Equal("Flag --threads has been deprecated, use --parallel instead\n"))
+	})
+
+	It("handles prefixed aliases", func() {
+		set.Add(flagutils.NewPrefixedOptions("target", parallel.New().WithAliases("threads")))
+		set.AddFlags(fs)
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--target-threads", "3"}))
+		Expect(flagutils.GetFrom[*parallel.Options](set, "target").Value()).To(Equal(3))
+		Expect(buf.String()).To(Equal("Flag --target-threads has been deprecated, use --target-parallel instead\n"))
+	})
+
+	It("deprecates flags", func() {
+		set.Add(parallel.New().WithDeprecation("it is always parallel now").WithShorthandDeprecation("use --parallel"))
+		set.AddFlags(fs)
+
+		Expect(fs.FlagUsages()).To(ContainSubstring("--parallel int   degree of parallelism (DEPRECATED: it is always parallel now)"))
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "3"}))
+		Expect(parallel.From(set).Value()).To(Equal(3))
+		Expect(buf.String()).To(Equal("Flag shorthand -p has been deprecated, use --parallel\nFlag --parallel has been deprecated, it is always parallel now\n"))
+	})
+
+	It("hides flags", func() {
+		set.Add(parallel.New().WithHidden())
+		set.AddFlags(fs)
+
+		Expect(fs.FlagUsages()).To(Equal(""))
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "3"}))
+		Expect(parallel.From(set).Value()).To(Equal(3))
+	})
+
+	It("applies attributes for general options", func() {
+		set.Add(tableoutput.New())
+		set.AddFlags(fs)
+		flagutils.ApplyFlagAttributes(fs, "columns", flagutils.FlagAttributes{Aliases: []string{"cols"}})
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--cols", "a,b"}))
+		Expect(tableoutput.From(set).UseColumns()).To(Equal([]string{"a", "b"}))
+	})
+
+	It("detects alias collisions", func() {
+		set.Add(
+			parallel.New().WithAliases("threads"),
+			parallel.New().WithNames("workers", "w").WithAliases("parallel", "threads"),
+		)
+		Expect(flagutils.Prepare(context.Background(), set, nil)).To(MatchError(
+			"flag alias collisions: alias --parallel of *parallel.Options collides with flag of *parallel.Options, alias --threads of *parallel.Options collides with alias of *parallel.Options"))
+	})
+	It("detects alias collisions with flags of other options", func() {
+		set.Add(
+			tableoutput.New(),
+			parallel.New().WithAliases("columns"),
+		)
+		MustBeSuccessful(flagutils.Prepare(context.Background(), set, nil))
+		Expect(flagutils.Check(set, pflag.NewFlagSet("test", pflag.ContinueOnError))).To(MatchError(
+			"flag collisions: --columns (*tableoutput.Options, *parallel.Options[alias of --parallel])"))
+	})
+})
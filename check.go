@@ -0,0 +1,220 @@
+package flagutils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mandelsoft/goutils/maputils"
+	"github.com/spf13/pflag"
+)
+
+// Collision describes a long flag name or shorthand
+// registered by multiple Options objects.
+type Collision struct {
+	Name      string
+	Shorthand bool
+	// Options are the descriptions of the responsible Options objects.
+	Options []string
+}
+
+func (c Collision) String() string {
+	if c.Shorthand {
+		return fmt.Sprintf("-%s (%s)", c.Name, strings.Join(c.Options, ", "))
+	}
+	return fmt.Sprintf("--%s (%s)", c.Name, strings.Join(c.Options, ", "))
+}
+
+// CollisionError is provided by Check for an OptionSet
+// with colliding flags.
+type CollisionError struct {
+	Collisions []Collision
+}
+
+func (e *CollisionError) Error() string {
+	var list []string
+	for _, c := range e.Collisions {
+		list = append(list, c.String())
+	}
+	return "flag collisions: " + strings.Join(list, ", ")
+}
+
+// ShorthandsOnly reports whether all collisions are
+// shorthand collisions, which can be resolved by dropping shorthands
+// (see COLLISION_DROP_SHORTHANDS).
+func (e *CollisionError) ShorthandsOnly() bool {
+	for _, c := range e.Collisions {
+		if !c.Shorthand {
+			return false
+		}
+	}
+	return true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CollisionPolicy describes the handling of flag collisions
+// detected by Check. The policy is configured by adding it
+// to the option set, for which the flags are added.
+type CollisionPolicy string
+
+const (
+	// COLLISION_FAIL reports all collisions as error (default).
+	COLLISION_FAIL CollisionPolicy = "fail"
+	// COLLISION_DROP_SHORTHANDS resolves shorthand collisions by dropping
+	// the shorthand for all but the first Options object using it.
+	COLLISION_DROP_SHORTHANDS CollisionPolicy = "drop shorthands"
+)
+
+var _ Options = COLLISION_FAIL
+
+// AddFlags does nothing, a CollisionPolicy is just
+// a marker for an option set.
+func (p CollisionPolicy) AddFlags(fs *pflag.FlagSet) {
+}
+
+// GetCollisionPolicy provides the CollisionPolicy configured
+// for an option set.
+func GetCollisionPolicy(opts OptionSetProvider) CollisionPolicy {
+	if p, ok := GetFrom2[CollisionPolicy](opts); ok {
+		return p
+	}
+	return COLLISION_FAIL
+}
+
+// FlagSetBinder is an optional interface for Options objects
+// requiring the pflag.FlagSet their flags are finally added to.
+// Because the flags of every Options object are added to a separate
+// scratch flag set first (see Check), the flag set passed to AddFlags
+// must not be kept. Instead, BindFlagSet is called with the final
+// flag set after all flags have been added.
+type FlagSetBinder interface {
+	BindFlagSet(fs *pflag.FlagSet)
+}
+
+// Check adds the flags of an OptionSet to a pflag.FlagSet, but detects
+// flag collisions, which would cause pflag to panic. It is used by the
+// AddFlags method of DefaultOptionSet and by ExecuteLifecycle.
+//
+// Therefore, the AddFlags method of every nested Options object is called
+// once with a separate scratch pflag.FlagSet. Its flags are annotated with
+// the responsible object (see IsFlagOf) and moved to the given flag set.
+// Afterward, the final flag set is bound to all FlagSetBinder objects.
+//
+// Long name and shorthand collisions are reported together with the
+// responsible Options objects by a CollisionError. Flags with colliding
+// names are omitted. Shorthand collisions are resolved by dropping the
+// shorthand of the flag added later, if the option set contains the
+// CollisionPolicy COLLISION_DROP_SHORTHANDS. Otherwise, they are reported.
+func Check(opts OptionSetProvider, fs *pflag.FlagSet) error {
+	return addChecked(opts, leafOptions(opts, nil), fs)
+}
+
+// addChecked adds the flags of the given nested Options objects of an
+// option set like Check. The CollisionPolicy and the FlagSetBinder objects
+// are taken from the complete option set.
+func addChecked(opts OptionSetProvider, leaves []Options, fs *pflag.FlagSet) error {
+	drop := GetCollisionPolicy(opts) == COLLISION_DROP_SHORTHANDS
+
+	names := map[string][]string{}
+	shorthands := map[string][]string{}
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		names[f.Name] = append(names[f.Name], describeFlag(f))
+		if f.Shorthand != "" {
+			shorthands[f.Shorthand] = append(shorthands[f.Shorthand], describeShorthand(f))
+		}
+	})
+
+	for _, o := range leaves {
+		scratch, err := addFlags(o, fs)
+		if err != nil {
+			return fmt.Errorf("flags of %s: %w", DescribeOptions(o), err)
+		}
+		annotateFlags(scratch, o)
+		scratch.VisitAll(func(f *pflag.Flag) {
+			names[f.Name] = append(names[f.Name], describeFlag(f))
+			if len(names[f.Name]) > 1 {
+				return
+			}
+			if f.Shorthand != "" {
+				if len(shorthands[f.Shorthand]) > 0 && drop {
+					f.Shorthand = ""
+					f.ShorthandDeprecated = ""
+				} else {
+					shorthands[f.Shorthand] = append(shorthands[f.Shorthand], describeShorthand(f))
+					if len(shorthands[f.Shorthand]) > 1 {
+						f.Shorthand = ""
+					}
+				}
+			}
+			fs.AddFlag(f)
+		})
+	}
+
+	for _, b := range Filter[FlagSetBinder](opts) {
+		b.BindFlagSet(fs)
+	}
+
+	var list []Collision
+	for _, n := range maputils.OrderedKeys(names) {
+		if len(names[n]) > 1 {
+			list = append(list, Collision{Name: n, Options: names[n]})
+		}
+	}
+	for _, n := range maputils.OrderedKeys(shorthands) {
+		if len(shorthands[n]) > 1 {
+			list = append(list, Collision{Name: n, Shorthand: true, Options: shorthands[n]})
+		}
+	}
+	if len(list) > 0 {
+		return &CollisionError{list}
+	}
+	return nil
+}
+
+// describeFlag describes the Options object responsible for a flag.
+func describeFlag(f *pflag.Flag) string {
+	desc := GetFlagOptions(f)
+	if desc == "" {
+		desc = "<unknown>"
+	}
+	if t := FlagAliasTarget(f); t != nil {
+		return fmt.Sprintf("%s[alias of --%s]", desc, t.Name)
+	}
+	return desc
+}
+
+func describeShorthand(f *pflag.Flag) string {
+	return fmt.Sprintf("%s[--%s]", describeFlag(f), f.Name)
+}
+
+// leafOptions provides the nested Options objects,
+// which are no option sets.
+func leafOptions(opts OptionSetProvider, list []Options) []Options {
+	for o := range opts.AsOptionSet().Options {
+		if s, ok := o.(OptionSetProvider); ok {
+			list = leafOptions(s, list)
+		} else {
+			list = append(list, o)
+		}
+	}
+	return list
+}
+
+// addFlags adds the flags of an Options object to a scratch flag set
+// using the settings of the final flag set.
+// A panic caused by the object itself is reported as error.
+func addFlags(o Options, fs *pflag.FlagSet) (scratch *pflag.FlagSet, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	scratch = pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)
+	scratch.SortFlags = false
+	scratch.SetOutput(io.Discard)
+	scratch.SetNormalizeFunc(fs.GetNormalizeFunc())
+	o.AddFlags(scratch)
+	return scratch, nil
+}
@@ -0,0 +1,145 @@
+package flagutils_test
+
+import (
+	"context"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/dump"
+	"github.com/mandelsoft/flagutils/parallel"
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+// rawOption adds its flag directly to the flag set.
+type rawOption struct {
+	value bool
+}
+
+func (o *rawOption) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVarP(&o.value, "raw", "p", false, "raw flag")
+}
+
+type runner struct {
+	opts flagutils.OptionSet
+}
+
+func (r *runner) Run(ctx context.Context, opts flagutils.OptionSet) error {
+	r.opts = opts
+	return nil
+}
+
+var _ = Describe("flag collisions", func() {
+	var fs *pflag.FlagSet
+
+	BeforeEach(func() {
+		fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	})
+
+	It("accepts valid sets", func() {
+		set := flagutils.NewOptionSet(parallel.New(), flagutils.NewPrefixedOptions("target", parallel.New()))
+		MustBeSuccessful(flagutils.Check(set, fs))
+		Expect(fs.Lookup("parallel")).NotTo(BeNil())
+		Expect(fs.Lookup("target-parallel")).NotTo(BeNil())
+	})
+
+	It("detects name collisions", func() {
+		set := flagutils.NewOptionSet(parallel.New(), flagutils.NewOptionSet(parallel.New().WithNames("parallel", "n")))
+		err := flagutils.Check(set, fs)
+		Expect(err).To(MatchError("flag collisions: --parallel (*parallel.Options, *parallel.Options)"))
+	})
+
+	It("detects collisions with flags already added", func() {
+		fs.Bool("parallel", false, "other flag")
+		err := flagutils.Check(flagutils.NewOptionSet(parallel.New()), fs)
+		Expect(err).To(MatchError("flag collisions: --parallel (<unknown>, *parallel.Options)"))
+	})
+
+	It("panics for collisions when adding the flags", func() {
+		set := flagutils.NewOptionSet(parallel.New(), parallel.New())
+		Expect(func() { set.AddFlags(fs) }).To(PanicWith(MatchError(ContainSubstring("flag collisions: --parallel"))))
+	})
+
+	It("detects shorthand collisions", func() {
+		set := flagutils.NewOptionSet(parallel.New(), flagutils.NewPrefixedOptions("target", parallel.New(), flagutils.KeepShorthands))
+		err := flagutils.Check(set, fs)
+		Expect(err).To(MatchError("flag collisions: -p (*parallel.Options[--parallel], *parallel.Options[target][--target-parallel])"))
+
+		var cerr *flagutils.CollisionError
+		Expect(err).To(BeAssignableToTypeOf(cerr))
+		Expect(err.(*flagutils.CollisionError).ShorthandsOnly()).To(BeTrue())
+	})
+
+	It("drops colliding shorthands", func() {
+		set := flagutils.NewOptionSet(parallel.New(), flagutils.NewOptionSet(parallel.New().WithNames("workers", "p")), flagutils.COLLISION_DROP_SHORTHANDS)
+		MustBeSuccessful(flagutils.Check(set, fs))
+
+		Expect(fs.Lookup("parallel").Shorthand).To(Equal("p"))
+		Expect(fs.Lookup("workers").Shorthand).To(Equal(""))
+		Expect(flagutils.GetFlagOptions(fs.Lookup("workers"))).To(Equal("*parallel.Options"))
+	})
+
+	It("keeps options bound to the flag set", func() {
+		d := dump.New().WithDumpNames("dump-options", "p")
+		set := flagutils.NewOptionSet(parallel.New(), d, flagutils.COLLISION_DROP_SHORTHANDS)
+		MustBeSuccessful(flagutils.Check(set, fs))
+		Expect(fs.Lookup("dump-options").Shorthand).To(Equal(""))
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "3"}))
+		doc := Must(d.Dump(set))
+		Expect(doc.Flags).To(ContainElement(HaveField("Name", "parallel")))
+	})
+
+	It("drops colliding shorthands of prefixed options", func() {
+		set := flagutils.NewOptionSet(parallel.New(), flagutils.NewPrefixedOptions("target", parallel.New(), flagutils.KeepShorthands), flagutils.COLLISION_DROP_SHORTHANDS)
+		MustBeSuccessful(flagutils.Check(set, fs))
+
+		Expect(fs.Lookup("parallel").Shorthand).To(Equal("p"))
+		Expect(fs.Lookup("target-parallel").Shorthand).To(Equal(""))
+	})
+
+	It("drops colliding shorthands of flags added directly", func() {
+		r := &rawOption{}
+		set := flagutils.NewOptionSet(parallel.New(), r, flagutils.COLLISION_DROP_SHORTHANDS)
+		MustBeSuccessful(flagutils.Check(set, fs))
+
+		Expect(fs.Lookup("raw").Shorthand).To(Equal(""))
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--raw"}))
+		Expect(r.value).To(BeTrue())
+	})
+
+	It("adds the flags only once", func() {
+		p := parallel.New()
+		rec := flagutils.NewFlagSetRecorder()
+		set := flagutils.NewOptionSet(p, rec)
+		MustBeSuccessful(flagutils.Check(set, fs))
+		Expect(rec.FlagSet()).To(BeIdenticalTo(fs))
+
+		p.Set(3)
+		Expect(flagutils.Provenance(set, "parallel").Kind).To(Equal(flagutils.ORIGIN_PROGRAMMATIC))
+	})
+
+	Context("lifecycle", func() {
+		It("fails for collisions", func() {
+			set := flagutils.NewOptionSet(parallel.New(), parallel.New().WithNames("workers", "p"))
+			Expect(flagutils.ExecuteLifecycle(context.Background(), "test", set, &runner{}, "-p", "2")).To(
+				MatchError("preparation failed: flag collisions: -p (*parallel.Options[--parallel], *parallel.Options[--workers])"))
+		})
+
+		It("resolves shorthand collisions", func() {
+			p := parallel.New()
+			w := parallel.New().WithNames("workers", "p")
+			set := flagutils.NewOptionSet(p, w, flagutils.COLLISION_DROP_SHORTHANDS)
+			MustBeSuccessful(flagutils.ExecuteLifecycle(context.Background(), "test", set, &runner{}, "-p", "2", "--workers", "3"))
+			Expect(p.Value()).To(Equal(2))
+			Expect(w.Value()).To(Equal(3))
+		})
+
+		It("does not resolve name collisions", func() {
+			set := flagutils.NewOptionSet(parallel.New(), parallel.New(), flagutils.COLLISION_DROP_SHORTHANDS)
+			Expect(flagutils.ExecuteLifecycle(context.Background(), "test", set, &runner{})).To(
+				MatchError(ContainSubstring("--parallel (*parallel.Options, *parallel.Options)")))
+		})
+	})
+})
@@ -0,0 +1,297 @@
+package flagutils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils/flagsets/groups"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// Command is a node of a command tree. It has a name, an optional
+// OptionSet and an optional Runner, and may have nested sub commands.
+// The options of a command are inherited by its sub commands. The options
+// of all commands on the path to the executed command are combined to a
+// single OptionSet. Therefore, sub commands may share Options objects of their
+// parents using OptionsRef or Assure.
+//
+// The sub command is selected by the positional arguments. All other
+// arguments, before and after the sub command names, are parsed for the
+// combined option set. The options of a command are prepared when the
+// command is selected, so the preparation phase is observed once per
+// command on the path.
+type Command struct {
+	name     string
+	short    string
+	group    string
+	options  OptionSetProvider
+	runner   Runner
+	parent   *Command
+	commands []*Command
+}
+
+func NewCommand(name string, opts OptionSetProvider, run Runner) *Command {
+	return &Command{name: name, options: opts, runner: run}
+}
+
+func (c *Command) WithShort(s string) *Command {
+	c.short = s
+	return c
+}
+
+// WithGroup sets the group used to list the command in the help
+// of its parent command.
+func (c *Command) WithGroup(g string) *Command {
+	c.group = g
+	return c
+}
+
+func (c *Command) AddCommands(cmds ...*Command) *Command {
+	for _, n := range cmds {
+		n.parent = c
+		c.commands = append(c.commands, n)
+	}
+	return c
+}
+
+func (c *Command) GetName() string {
+	return c.name
+}
+
+func (c *Command) GetShort() string {
+	return c.short
+}
+
+func (c *Command) GetParent() *Command {
+	return c.parent
+}
+
+func (c *Command) GetCommands() []*Command {
+	return c.commands
+}
+
+func (c *Command) GetCommand(name string) *Command {
+	for _, n := range c.commands {
+		if n.name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// GetPath provides the names of the commands from the root to this command.
+func (c *Command) GetPath() string {
+	if c.parent == nil {
+		return c.name
+	}
+	return c.parent.GetPath() + " " + c.name
+}
+
+func (c *Command) path() []*Command {
+	if c.parent == nil {
+		return []*Command{c}
+	}
+	return append(c.parent.path(), c)
+}
+
+// OptionSet provides a new OptionSet combining the options
+// of all commands from the root to this command.
+func (c *Command) OptionSet() ExtendableOptionSet {
+	set := NewOptionSet()
+	for _, n := range c.path() {
+		if n.options != nil {
+			set.Add(n.options.AsOptionSet())
+		}
+	}
+	return set
+}
+
+// resolve selects the command for the given arguments.
+// It returns the selected command and the remaining arguments without
+// the sub command names. Additionally, it reports whether help is requested
+// by -h or --help.
+// The option sets of the commands on the path are added to the lifecycle
+// by the add function when the command is selected, so that their flags
+// are known for the selection of sub commands.
+// Flags of Options objects added to the option set during the preparation
+// are known for the selection after the command adding them is selected.
+func (c *Command) resolve(l *lifecycle, args []string, add func(c *Command) error) (*Command, []string, bool, error) {
+	cur := c
+	var rest []string
+	help := false
+	fs := l.fs
+
+	if err := add(cur); err != nil {
+		return nil, nil, false, err
+	}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			return cur, append(rest, args[i:]...), help, nil
+		case strings.HasPrefix(a, "--"):
+			rest = append(rest, a)
+			name, _, found := strings.Cut(a[2:], "=")
+			f := fs.Lookup(name)
+			if f == nil && name == "help" {
+				help = true
+			}
+			if !found && f != nil && f.NoOptDefVal == "" && i+1 < len(args) {
+				i++
+				rest = append(rest, args[i])
+			}
+		case strings.HasPrefix(a, "-") && len(a) > 1:
+			rest = append(rest, a)
+			for j := 1; j < len(a); j++ {
+				f := fs.ShorthandLookup(a[j : j+1])
+				if f == nil {
+					if a[j] == 'h' {
+						help = true
+					}
+					continue
+				}
+				if f.NoOptDefVal == "" {
+					if j == len(a)-1 && i+1 < len(args) {
+						i++
+						rest = append(rest, args[i])
+					}
+					break
+				}
+			}
+		default:
+			if n := cur.GetCommand(a); n != nil {
+				cur = n
+				if err := add(cur); err != nil {
+					return nil, nil, false, err
+				}
+				continue
+			}
+			if cur.runner == nil {
+				return cur, nil, help, fmt.Errorf("unknown sub command %q for %q", a, cur.GetPath())
+			}
+			return cur, append(rest, args[i:]...), help, nil
+		}
+	}
+	return cur, rest, help, nil
+}
+
+// Execute selects the command for the given arguments and executes
+// its lifecycle like ExecuteLifecycle.
+// If help is requested, or the selected command has no runner,
+// the help for the command is written to the output context.
+func (c *Command) Execute(ctx context.Context, args ...string) error {
+	return NewLifecycleRunner(c.name).ExecuteCommand(ctx, c, args...)
+}
+
+// ExecuteCommand executes a command tree like Command.Execute,
+// but uses the settings of the LifecycleRunner.
+// The options of the commands on the path to the selected command
+// are prepared and added to the flag set while the command is selected.
+// The same flag set is used to parse the arguments.
+func (r *LifecycleRunner) ExecuteCommand(ctx context.Context, c *Command, args ...string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l := newLifecycle(ctx, c.name)
+	cmd, args, help, err := c.resolve(l, args, func(n *Command) error {
+		return r.call(PHASE_PREPARE, func() error { return l.add(ctx, n.options) })
+	})
+	if err != nil {
+		return err
+	}
+	if help {
+		return cmd.help(ctx, l)
+	}
+	if cmd.runner == nil {
+		if len(cmd.commands) > 0 {
+			if err := cmd.help(ctx, l); err != nil {
+				return err
+			}
+			return fmt.Errorf("sub command required for %q", cmd.GetPath())
+		}
+		return fmt.Errorf("no runner for %q", cmd.GetPath())
+	}
+	l.fs.Init(cmd.GetPath(), pflag.ContinueOnError)
+	return r.execute(ctx, func(ctx context.Context) (*lifecycle, error) { return l, nil }, cmd.runner, args...)
+}
+
+// Help writes the help for the command to the output context.
+// Own and inherited flags are listed separately, grouped
+// according to the group annotations of package flagsets/groups.
+func (c *Command) Help(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l := newLifecycle(ctx, c.GetPath())
+	for _, n := range c.path() {
+		if err := l.add(ctx, n.options); err != nil {
+			return err
+		}
+	}
+	return c.help(ctx, l)
+}
+
+// help writes the help for the command based on the flags
+// added to the lifecycle for the commands on its path.
+func (c *Command) help(ctx context.Context, l *lifecycle) error {
+	own := pflag.NewFlagSet(c.name, pflag.ContinueOnError)
+	inh := pflag.NewFlagSet(c.name, pflag.ContinueOnError)
+	l.fs.VisitAll(func(f *pflag.Flag) {
+		if l.inherited.Has(f.Name) {
+			inh.AddFlag(f)
+		} else {
+			own.AddFlag(f)
+		}
+	})
+
+	buf := &bytes.Buffer{}
+	if c.short != "" {
+		fmt.Fprintf(buf, "%s\n\n", c.short)
+	}
+	fmt.Fprintf(buf, "Usage:\n")
+	if c.runner != nil {
+		fmt.Fprintf(buf, "  %s [<options>] [<args>]\n", c.GetPath())
+	}
+	if len(c.commands) > 0 {
+		fmt.Fprintf(buf, "  %s [<options>] <sub command> ...\n", c.GetPath())
+		c.commandHelp(buf)
+	}
+	if own.HasAvailableFlags() {
+		fmt.Fprintf(buf, "\nOptions:\n%s", groups.FlagUsagesWrapped(own, 0))
+	}
+	if inh.HasAvailableFlags() {
+		fmt.Fprintf(buf, "\nInherited Options:\n%s", groups.FlagUsagesWrapped(inh, 0))
+	}
+	if u, ok := l.opts.(Usage); ok && u.Usage() != "" {
+		fmt.Fprintf(buf, "\n%s\n", strings.TrimSpace(u.Usage()))
+	}
+	_, err := out.Write(ctx, buf.Bytes())
+	return err
+}
+
+func (c *Command) commandHelp(buf *bytes.Buffer) {
+	var titles []string
+	cmds := map[string][]*Command{}
+	width := 0
+	for _, n := range c.commands {
+		if _, ok := cmds[n.group]; !ok {
+			titles = append(titles, n.group)
+		}
+		cmds[n.group] = append(cmds[n.group], n)
+		width = max(width, len(n.name))
+	}
+	for _, t := range titles {
+		title := t
+		if title == "" {
+			title = "Sub Commands"
+		}
+		fmt.Fprintf(buf, "\n%s:\n", title)
+		for _, n := range cmds[t] {
+			fmt.Fprintf(buf, "  %-*s   %s\n", width, n.name, n.short)
+		}
+	}
+}
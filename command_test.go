@@ -0,0 +1,155 @@
+package flagutils_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/utils/out"
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+type recorder struct {
+	opts flagutils.OptionSet
+	args []string
+}
+
+func (r *recorder) runner(cmd string) flagutils.Runner {
+	return RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+		r.opts = opts
+		r.args = append([]string{cmd}, flagutils.GetFlagSet(opts).Args()...)
+		return nil
+	})
+}
+
+// countingOption counts the calls of its lifecycle methods.
+type countingOption struct {
+	flag     bool
+	added    int
+	prepared int
+}
+
+func (o *countingOption) AddFlags(fs *pflag.FlagSet) {
+	o.added++
+	fs.BoolVar(&o.flag, "count", false, "counting flag")
+}
+
+func (o *countingOption) Prepare(ctx context.Context, opts flagutils.OptionSet, v flagutils.PreparationSet) error {
+	o.prepared++
+	return nil
+}
+
+var _ = Describe("command tree", func() {
+	var ctx context.Context
+	var buf *bytes.Buffer
+	var rec *recorder
+	var root *flagutils.Command
+	var shared *flagutils.OptionsRef[*parallel.Options]
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		ctx = out.With(context.Background(), out.New(buf, os.Stderr))
+		rec = &recorder{}
+		shared = flagutils.NewOptionsRef[*parallel.Options](func() *parallel.Options { return parallel.New() })
+
+		root = flagutils.NewCommand("app", flagutils.NewOptionSet(parallel.New()), nil).WithShort("demo application")
+		root.AddCommands(
+			flagutils.NewCommand("list", flagutils.NewOptionSet(tableoutput.New()), rec.runner("list")).
+				WithShort("list elements"),
+			flagutils.NewCommand("show", flagutils.NewOptionSet(shared), rec.runner("show")).
+				WithShort("show element"),
+			flagutils.NewCommand("admin", nil, nil).WithShort("administration").WithGroup("Management").
+				AddCommands(flagutils.NewCommand("reset", nil, rec.runner("reset"))),
+		)
+	})
+
+	It("dispatches on positional arguments", func() {
+		MustBeSuccessful(root.Execute(ctx, "-p", "3", "list", "--columns", "a,b", "arg"))
+		Expect(rec.args).To(Equal([]string{"list", "arg"}))
+		Expect(parallel.From(rec.opts).Value()).To(Equal(3))
+		Expect(tableoutput.From(rec.opts).UseColumns()).To(Equal([]string{"a", "b"}))
+	})
+
+	It("accepts inherited options after the sub command", func() {
+		MustBeSuccessful(root.Execute(ctx, "list", "-p3", "show"))
+		Expect(rec.args).To(Equal([]string{"list", "show"}))
+		Expect(parallel.From(rec.opts).Value()).To(Equal(3))
+	})
+
+	It("shares parent options", func() {
+		MustBeSuccessful(root.Execute(ctx, "show", "--parallel=2"))
+		Expect(rec.args).To(Equal([]string{"show"}))
+		Expect(shared.Options).NotTo(BeNil())
+		Expect(shared.Options.Value()).To(Equal(2))
+	})
+
+	It("dispatches nested commands", func() {
+		MustBeSuccessful(root.Execute(ctx, "admin", "reset", "x"))
+		Expect(rec.args).To(Equal([]string{"reset", "x"}))
+	})
+
+	It("rejects unknown commands", func() {
+		Expect(root.Execute(ctx, "-p", "list", "other")).To(MatchError(`unknown sub command "other" for "app"`))
+		Expect(root.Execute(ctx, "other")).To(MatchError(`unknown sub command "other" for "app"`))
+		Expect(root.Execute(ctx, "admin")).To(MatchError(`sub command required for "app admin"`))
+	})
+
+	It("generates help", func() {
+		MustBeSuccessful(root.Execute(ctx, "--help"))
+		Expect(buf.String()).To(Equal(`demo application
+
+Usage:
+  app [<options>] <sub command> ...
+
+Sub Commands:
+  list    list elements
+  show    show element
+
+Management:
+  admin   administration
+
+Options:
+  -p, --parallel int   degree of parallelism
+`))
+	})
+
+	It("generates help for sub commands", func() {
+		MustBeSuccessful(root.Execute(ctx, "list", "-h"))
+		Expect(buf.String()).To(Equal(`list elements
+
+Usage:
+  app list [<options>] [<args>]
+
+Options:
+      --columns strings   show selected columns
+
+Inherited Options:
+  -p, --parallel int   degree of parallelism
+`))
+	})
+
+	It("adds the options once", func() {
+		c := &countingOption{}
+		root.AddCommands(flagutils.NewCommand("count", flagutils.NewOptionSet(c), rec.runner("count")))
+		MustBeSuccessful(root.Execute(ctx, "-p", "2", "count", "--count"))
+		Expect(c.flag).To(BeTrue())
+		Expect(c.added).To(Equal(1))
+		Expect(c.prepared).To(Equal(1))
+		Expect(flagutils.GetFlagSet(rec.opts).Lookup("count").Changed).To(BeTrue())
+
+		MustBeSuccessful(root.Execute(ctx, "count", "--help"))
+		Expect(c.added).To(Equal(2))
+		Expect(c.prepared).To(Equal(2))
+	})
+
+	It("executes with lifecycle runner", func() {
+		MustBeSuccessful(flagutils.NewLifecycleRunner("app").ExecuteCommand(ctx, root, "list", "-p", "4"))
+		Expect(parallel.From(rec.opts).Value()).To(Equal(4))
+	})
+})
@@ -0,0 +1,161 @@
+// Package destination provides an Options type redirecting the
+// standard output of the output context to a file.
+package destination
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// Options redirects the standard output of the output context (see
+// package utils/out) used for the run phase to a file.
+// The output is written to a temporary file in the directory of the
+// target file, which is renamed to the target file by Finalize, so the
+// target file is replaced atomically. If the run fails, the temporary
+// file is removed and the target file is left untouched.
+type Options struct {
+	file flagutils.SimpleOption[string, *Options]
+	app  flagutils.SimpleOption[bool, *Options]
+	tee  flagutils.SimpleOption[bool, *Options]
+
+	temp *os.File
+}
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+var (
+	_ flagutils.Options         = (*Options)(nil)
+	_ flagutils.Validatable     = (*Options)(nil)
+	_ flagutils.ContextProvider = (*Options)(nil)
+	_ flagutils.Finalizable     = (*Options)(nil)
+)
+
+func New() *Options {
+	o := &Options{}
+	o.file = flagutils.NewSimpleOption[string](o, "", "output-file", "", "write output to file")
+	o.app = flagutils.NewSimpleOption[bool](o, false, "append", "", "append output to output file")
+	o.tee = flagutils.NewSimpleOption[bool](o, false, "tee", "", "additionally write output file content to stdout")
+	return o
+}
+
+func (o *Options) WithFileNames(long, short string) *Options {
+	return o.file.WithNames(long, short)
+}
+
+func (o *Options) WithFileDescription(s string) *Options {
+	return o.file.WithDescription(s)
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.file.AddFlags(fs)
+	o.app.AddFlags(fs)
+	o.tee.AddFlags(fs)
+}
+
+// GetFile provides the path of the output file.
+// It is empty if no output file is requested.
+func (o *Options) GetFile() string {
+	return o.file.Value()
+}
+
+func (o *Options) IsAppend() bool {
+	return o.app.Value()
+}
+
+func (o *Options) IsTee() bool {
+	return o.tee.Value()
+}
+
+func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flagutils.ValidationSet) error {
+	if o.GetFile() != "" {
+		return nil
+	}
+	file, _ := o.file.GetNames()
+	for _, f := range []*flagutils.SimpleOption[bool, *Options]{&o.app, &o.tee} {
+		if f.Value() {
+			name, _ := f.GetNames()
+			return fmt.Errorf("--%s requires --%s", name, file)
+		}
+	}
+	return nil
+}
+
+// ProvideContext creates the temporary output file and
+// provides an output context writing to it.
+func (o *Options) ProvideContext(ctx context.Context, opts flagutils.OptionSet) (context.Context, error) {
+	path := o.GetFile()
+	if path == "" {
+		return ctx, nil
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create output file: %w", err)
+	}
+	o.temp = temp
+
+	if o.IsAppend() {
+		if err := o.copy(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var w io.Writer = temp
+	if o.IsTee() {
+		w = io.MultiWriter(temp, out.Get(ctx).Stdout())
+	}
+	return out.With(ctx, out.New(w, nil)), nil
+}
+
+// copy copies the actual content of the target file
+// to the temporary file.
+func (o *Options) copy(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(o.temp, src)
+	return err
+}
+
+// Finalize closes the temporary output file. It replaces the
+// target file, if the lifecycle has been executed successfully
+// (see flagutils.GetFailure). Otherwise, it is removed.
+func (o *Options) Finalize(ctx context.Context, opts flagutils.OptionSet, v flagutils.FinalizationSet) error {
+	if o.temp == nil {
+		return nil
+	}
+	temp := o.temp.Name()
+	err := o.temp.Close()
+	o.temp = nil
+
+	if err == nil && flagutils.GetFailure(ctx) == nil {
+		mode := os.FileMode(0o644)
+		if fi, serr := os.Stat(o.GetFile()); serr == nil {
+			mode = fi.Mode().Perm()
+		}
+		err = os.Chmod(temp, mode)
+		if err == nil {
+			err = os.Rename(temp, o.GetFile())
+		}
+		if err == nil {
+			return nil
+		}
+		err = fmt.Errorf("cannot write output file: %w", err)
+	}
+	return errors.Join(err, os.Remove(temp))
+}
@@ -0,0 +1,97 @@
+package destination_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/destination"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+var _ = Describe("destination options", func() {
+	var dir, path string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		path = filepath.Join(dir, "out.txt")
+	})
+
+	run := func(err error, args ...string) *flagutilstest.Result {
+		set := flagutils.NewOptionSet(destination.New())
+		return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			out.Print(ctx, "output\n")
+			Expect(os.ReadFile(path)).To(Equal([]byte("old\n")))
+			return err
+		}), args...)
+	}
+
+	Context("with existing file", func() {
+		BeforeEach(func() {
+			MustBeSuccessful(os.WriteFile(path, []byte("old\n"), 0o600))
+		})
+
+		It("replaces the file", func() {
+			r := run(nil, "--output-file", path)
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal(""))
+			Expect(os.ReadFile(path)).To(Equal([]byte("output\n")))
+
+			fi := Must(os.Stat(path))
+			Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+			Expect(os.ReadDir(dir)).To(HaveLen(1))
+		})
+
+		It("appends to the file", func() {
+			r := run(nil, "--output-file", path, "--append")
+			Expect(r.Error()).To(Succeed())
+			Expect(os.ReadFile(path)).To(Equal([]byte("old\noutput\n")))
+		})
+
+		It("writes to stdout", func() {
+			r := run(nil, "--output-file", path, "--tee")
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal("output\n"))
+			Expect(os.ReadFile(path)).To(Equal([]byte("output\n")))
+		})
+
+		It("keeps the file on failure", func() {
+			r := run(fmt.Errorf("failed"), "--output-file", path)
+			Expect(r.RunError).To(MatchError("failed"))
+			Expect(r.FinalizeError).To(Succeed())
+			Expect(os.ReadFile(path)).To(Equal([]byte("old\n")))
+			Expect(os.ReadDir(dir)).To(HaveLen(1))
+		})
+	})
+
+	It("creates a new file", func() {
+		set := flagutils.NewOptionSet(destination.New())
+		r := flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			out.Print(ctx, "output\n")
+			return nil
+		}), "--output-file", path)
+		Expect(r.Error()).To(Succeed())
+		Expect(os.ReadFile(path)).To(Equal([]byte("output\n")))
+	})
+
+	It("writes to stdout without file", func() {
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(destination.New()), flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			out.Print(ctx, "output\n")
+			return nil
+		}))
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("output\n"))
+	})
+
+	It("rejects flags without file", func() {
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(destination.New()), nil, "--tee")
+		Expect(r.ValidationError).To(MatchError("--tee requires --output-file (--tee from command line)"))
+	})
+})
@@ -0,0 +1,98 @@
+package doc_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/doc"
+	"github.com/mandelsoft/flagutils/flagsets"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/parallel"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type ObjectOptions struct {
+	provider flagsets.TypedOptionSetConfigProvider
+	options  flagsets.Options
+}
+
+var _ doc.OptionTypeSetProvider = (*ObjectOptions)(nil)
+
+func NewObjectOptions() *ObjectOptions {
+	p := flagsets.NewTypedConfigProvider("object", "object specification", "objectType")
+	Expect(p.AddTypeSet(flagsets.NewOptionTypeSet("typeA", flagsets.NewStringOptionType("attra", "attribute a")))).To(Succeed())
+	Expect(p.AddTypeSet(flagsets.NewOptionTypeSet("typeB", flagsets.NewStringOptionType("attrb", "attribute b")))).To(Succeed())
+	p.AddGroups("Object Options")
+	return &ObjectOptions{provider: p}
+}
+
+func (o *ObjectOptions) GetOptionTypeSet() flagsets.OptionTypeSet {
+	return o.provider
+}
+
+func (o *ObjectOptions) AddFlags(fs *pflag.FlagSet) {
+	o.options = o.provider.CreateOptions()
+	o.options.AddFlags(fs)
+}
+
+func prepare(opts ...flagutils.Options) flagutils.OptionSet {
+	set := flagutils.NewOptionSet(flagutils.NewFlagSetRecorder()).Add(opts...)
+	MustBeSuccessful(flagutils.Prepare(context.Background(), set, nil))
+	set.AddFlags(pflag.NewFlagSet("demo", pflag.ContinueOnError))
+	return set
+}
+
+func page() *doc.Page {
+	opts := prepare(
+		parallel.New(),
+		output.New(output.NewOutputsFactory[int]().AddManifestOutputs()),
+		NewObjectOptions(),
+	)
+	return Must(doc.NewPage("demo", opts)).
+		WithShort("demo command").
+		WithSynopsis("[<options>] <name>").
+		WithDescription("Demonstrate documentation.")
+}
+
+var _ = Describe("documentation", func() {
+	It("renders markdown", func() {
+		buf := &bytes.Buffer{}
+		MustBeSuccessful(page().Markdown(buf))
+		Expect(buf.String()).To(Equal(string(Must(os.ReadFile("testdata/demo.md")))))
+	})
+
+	It("renders man page", func() {
+		buf := &bytes.Buffer{}
+		MustBeSuccessful(page().Man(buf))
+		Expect(buf.String()).To(Equal(string(Must(os.ReadFile("testdata/demo.1")))))
+	})
+
+	It("escapes roff requests", func() {
+		buf := &bytes.Buffer{}
+		p := Must(doc.NewPage("demo", prepare())).WithDescription(".start\\n")
+		MustBeSuccessful(p.Man(buf))
+		Expect(buf.String()).To(ContainSubstring("\n.SH DESCRIPTION\n\\&.start\\en\n"))
+	})
+
+	It("uses the recorded flag set", func() {
+		obj := NewObjectOptions()
+		opts := prepare(parallel.New(), obj)
+		bound := obj.options
+
+		p := Must(doc.NewPage("demo", opts))
+		Expect(obj.options).To(BeIdenticalTo(bound))
+		Expect(p.Groups).NotTo(BeEmpty())
+	})
+
+	It("requires the recorded flag set", func() {
+		_, err := doc.NewPage("demo", flagutils.NewOptionSet(parallel.New()))
+		Expect(err).To(MatchError("no flag set recorded for option set"))
+	})
+})
@@ -0,0 +1,104 @@
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Man renders the page as roff man page.
+func (p *Page) Man(w io.Writer) error {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, ".TH %q %q \"\" \"\" \"\"\n", strings.ToUpper(p.Name), p.Section)
+	fmt.Fprintf(buf, ".SH NAME\n")
+	if p.Short != "" {
+		fmt.Fprintf(buf, "%s \\- %s\n", roffEscape(p.Name), roffText(p.Short))
+	} else {
+		fmt.Fprintf(buf, "%s\n", roffEscape(p.Name))
+	}
+
+	fmt.Fprintf(buf, ".SH SYNOPSIS\n.B %s\n", roffEscape(p.Name))
+	if p.Synopsis != "" {
+		fmt.Fprintf(buf, "%s\n", roffText(p.Synopsis))
+	}
+
+	if desc := paragraphs(p.Description + "\n\n" + p.Usage); len(desc) > 0 {
+		fmt.Fprintf(buf, ".SH DESCRIPTION\n")
+		for i, d := range desc {
+			if i > 0 {
+				fmt.Fprintf(buf, ".PP\n")
+			}
+			fmt.Fprintf(buf, "%s\n", roffText(d))
+		}
+	}
+
+	if len(p.Groups) > 0 {
+		fmt.Fprintf(buf, ".SH OPTIONS\n")
+		for _, g := range p.Groups {
+			if g.Title != "" {
+				fmt.Fprintf(buf, ".SS %s\n", roffText(g.Title))
+			}
+			for _, f := range g.Flags {
+				fmt.Fprintf(buf, ".TP\n\\fB%s\\fR", roffEscape(flagNames(f)))
+				if f.Type != "" {
+					fmt.Fprintf(buf, " \\fI%s\\fR", roffEscape(f.Type))
+				}
+				fmt.Fprintln(buf)
+				usage := f.Usage
+				if f.Default != "" {
+					usage += fmt.Sprintf(" (default <code>%s</code>)", f.Default)
+				}
+				fmt.Fprintf(buf, "%s\n", roffText(strings.TrimSpace(usage)))
+			}
+		}
+	}
+
+	if len(p.Modes) > 0 {
+		fmt.Fprintf(buf, ".SH MODES\n")
+		for _, m := range p.Modes {
+			fmt.Fprintf(buf, ".PP\nThe option \\fB\\-\\-%s\\fR supports the following modes:\n", roffEscape(m.Flag))
+			for _, n := range m.Modes {
+				fmt.Fprintf(buf, ".IP \\(bu 2\n\\fB%s\\fR\n", roffEscape(n))
+			}
+		}
+	}
+
+	if len(p.TypeSets) > 0 {
+		fmt.Fprintf(buf, ".SH CONFIGURATION TYPES\n")
+		for _, t := range p.TypeSets {
+			manTypeSet(buf, "", t)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func manTypeSet(buf *bytes.Buffer, prefix string, t TypeSet) {
+	fmt.Fprintf(buf, ".SS %s\n", roffEscape(prefix+t.Name))
+	if t.Description != "" {
+		fmt.Fprintf(buf, "%s\n", roffText(t.Description))
+	}
+	for _, n := range t.Nested {
+		manTypeSet(buf, prefix+t.Name+"/", n)
+	}
+}
+
+// roffText escapes text for roff and maps <code> markup to bold font.
+func roffText(s string) string {
+	s = roffEscape(s)
+	s = strings.NewReplacer("<code>", "\\fB", "</code>", "\\fR").Replace(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = "\\&" + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func roffEscape(s string) string {
+	return strings.NewReplacer("\\", "\\e", "-", "\\-").Replace(s)
+}
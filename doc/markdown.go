@@ -0,0 +1,81 @@
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Markdown renders the page as Markdown document.
+func (p *Page) Markdown(w io.Writer) error {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "# %s\n", p.Name)
+	if p.Short != "" {
+		fmt.Fprintf(buf, "\n%s\n", p.Short)
+	}
+
+	fmt.Fprintf(buf, "\n## Synopsis\n\n```\n%s\n```\n", strings.TrimSpace(p.Name+" "+p.Synopsis))
+
+	if desc := paragraphs(p.Description + "\n\n" + p.Usage); len(desc) > 0 {
+		fmt.Fprintf(buf, "\n## Description\n")
+		for _, d := range desc {
+			fmt.Fprintf(buf, "\n%s\n", markdownText(d))
+		}
+	}
+
+	if len(p.Groups) > 0 {
+		fmt.Fprintf(buf, "\n## Options\n")
+		for _, g := range p.Groups {
+			if g.Title != "" {
+				fmt.Fprintf(buf, "\n### %s\n", g.Title)
+			}
+			fmt.Fprintln(buf)
+			for _, f := range g.Flags {
+				fmt.Fprintf(buf, "- `%s`", strings.TrimSpace(flagNames(f)+" "+f.Type))
+				if f.Usage != "" {
+					fmt.Fprintf(buf, ": %s", markdownText(f.Usage))
+				}
+				if f.Default != "" {
+					fmt.Fprintf(buf, " (default `%s`)", f.Default)
+				}
+				fmt.Fprintln(buf)
+			}
+		}
+	}
+
+	if len(p.Modes) > 0 {
+		fmt.Fprintf(buf, "\n## Modes\n")
+		for _, m := range p.Modes {
+			fmt.Fprintf(buf, "\nThe option `--%s` supports the following modes:\n\n", m.Flag)
+			for _, n := range m.Modes {
+				fmt.Fprintf(buf, "- `%s`\n", n)
+			}
+		}
+	}
+
+	if len(p.TypeSets) > 0 {
+		fmt.Fprintf(buf, "\n## Configuration Types\n")
+		for _, t := range p.TypeSets {
+			markdownTypeSet(buf, "###", t)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func markdownTypeSet(buf *bytes.Buffer, level string, t TypeSet) {
+	fmt.Fprintf(buf, "\n%s %s\n", level, t.Name)
+	if t.Description != "" {
+		fmt.Fprintf(buf, "\n%s\n", markdownText(t.Description))
+	}
+	for _, n := range t.Nested {
+		markdownTypeSet(buf, level+"#", n)
+	}
+}
+
+func markdownText(s string) string {
+	return strings.NewReplacer("<code>", "`", "</code>", "`").Replace(s)
+}
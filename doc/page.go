@@ -0,0 +1,213 @@
+// Package doc generates reference documentation for command line
+// tools from a flagutils.OptionSet. A Page is collected from the
+// option set and can be rendered as Markdown or as roff man page.
+// The output does not contain any volatile information, so that
+// generated documents can be checked in.
+package doc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mandelsoft/goutils/maputils"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagsets"
+	"github.com/mandelsoft/flagutils/flagsets/groups"
+)
+
+// ModesProvider is implemented by Options objects offering
+// a set of modes for a flag, for example, the output Options.
+type ModesProvider interface {
+	GetModes() []string
+	GetNames() (string, string)
+}
+
+// ModeParameterProvider may be implemented by a ModesProvider
+// offering modes accepting a parameter.
+type ModeParameterProvider interface {
+	GetParameterHelp(mode string) (string, bool)
+}
+
+// OptionTypeSetProvider may be implemented by Options objects
+// configuring objects by a flagsets.OptionTypeSet.
+type OptionTypeSetProvider interface {
+	GetOptionTypeSet() flagsets.OptionTypeSet
+}
+
+// Flag describes a single documented flag.
+type Flag struct {
+	Name      string
+	Shorthand string
+	Type      string
+	Default   string
+	Usage     string
+}
+
+// Group describes the flags of a flag group.
+// Flags without group are described by the group with the empty title.
+type Group struct {
+	Title string
+	Flags []Flag
+}
+
+// Modes describes the modes supported by a flag.
+type Modes struct {
+	Flag  string
+	Modes []string
+}
+
+// TypeSet describes the options of a flagsets.OptionTypeSet
+// and its nested sets. The description is provided by flagsets.FormatOptions.
+type TypeSet struct {
+	Name        string
+	Description string
+	Nested      []TypeSet
+}
+
+// Page is the documentation model for a command.
+type Page struct {
+	Name        string
+	Section     string
+	Short       string
+	Synopsis    string
+	Description string
+	Usage       string
+
+	Groups   []Group
+	Modes    []Modes
+	TypeSets []TypeSet
+}
+
+// NewPage collects the documentation for a command from an OptionSet.
+// The flags are taken from the pflag.FlagSet recorded for the option set
+// (see flagutils.FlagSetRecorder), therefore, the option set must
+// already be prepared and added to a flag set.
+// Additional flagsets.OptionTypeSets to document can be given.
+func NewPage(name string, opts flagutils.OptionSet, sets ...flagsets.OptionTypeSet) (*Page, error) {
+	fs := flagutils.GetFlagSet(opts)
+	if fs == nil {
+		return nil, fmt.Errorf("no flag set recorded for option set")
+	}
+
+	p := &Page{Name: name, Section: "1"}
+	if u, ok := opts.(flagutils.Usage); ok {
+		p.Usage = u.Usage()
+	}
+	p.Groups = collectGroups(fs)
+
+	for _, m := range flagutils.Filter[ModesProvider](opts) {
+		long, _ := m.GetNames()
+		modes := slices.Clone(m.GetModes())
+		if h, ok := m.(ModeParameterProvider); ok {
+			for i, n := range modes {
+				if help, ok := h.GetParameterHelp(n); ok {
+					modes[i] = n + "=" + help
+				}
+			}
+		}
+		p.Modes = append(p.Modes, Modes{Flag: long, Modes: modes})
+	}
+
+	for _, t := range flagutils.Filter[OptionTypeSetProvider](opts) {
+		sets = append(sets, t.GetOptionTypeSet())
+	}
+	for _, s := range sets {
+		p.TypeSets = append(p.TypeSets, collectTypeSet(s))
+	}
+	return p, nil
+}
+
+func (p *Page) WithSection(s string) *Page {
+	p.Section = s
+	return p
+}
+
+func (p *Page) WithShort(s string) *Page {
+	p.Short = s
+	return p
+}
+
+func (p *Page) WithSynopsis(s string) *Page {
+	p.Synopsis = s
+	return p
+}
+
+func (p *Page) WithDescription(s string) *Page {
+	p.Description = s
+	return p
+}
+
+func collectGroups(fs *pflag.FlagSet) []Group {
+	flags := map[string][]Flag{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" {
+			return
+		}
+		typ, usage := pflag.UnquoteUsage(f)
+		d := Flag{
+			Name:  f.Name,
+			Type:  typ,
+			Usage: usage,
+		}
+		if f.ShorthandDeprecated == "" {
+			d.Shorthand = f.Shorthand
+		}
+		if !isZero(f.DefValue) {
+			d.Default = f.DefValue
+		}
+		titles := []string{""}
+		if g := f.Annotations[groups.FlagGroupAnnotation]; len(g) > 0 {
+			titles = g
+		}
+		for _, t := range titles {
+			flags[t] = append(flags[t], d)
+		}
+	})
+
+	var list []Group
+	for _, t := range maputils.OrderedKeys(flags) {
+		list = append(list, Group{Title: t, Flags: flags[t]})
+	}
+	return list
+}
+
+func isZero(v string) bool {
+	switch v {
+	case "", "false", "0", "[]", "map[]", "<nil>":
+		return true
+	}
+	return false
+}
+
+func collectTypeSet(s flagsets.OptionTypeSet) TypeSet {
+	t := TypeSet{Name: s.GetName(), Description: strings.TrimSpace(flagsets.FormatOptions(s))}
+	nested := map[string]TypeSet{}
+	for _, n := range s.OptionTypeSets() {
+		nested[n.GetName()] = collectTypeSet(n)
+	}
+	for _, n := range maputils.OrderedKeys(nested) {
+		t.Nested = append(t.Nested, nested[n])
+	}
+	return t
+}
+
+func flagNames(f Flag) string {
+	s := "--" + f.Name
+	if f.Shorthand != "" {
+		s = "-" + f.Shorthand + ", " + s
+	}
+	return s
+}
+
+func paragraphs(s string) []string {
+	var list []string
+	for _, p := range strings.Split(strings.TrimSpace(s), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
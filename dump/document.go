@@ -0,0 +1,206 @@
+package dump
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/set"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+const (
+	FORMAT_YAML = "yaml"
+	FORMAT_JSON = "json"
+)
+
+// Entry describes the effective state of a single flag.
+// Source describes the Options object responsible for the flag
+// and Provenance the origin of the value.
+type Entry struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Value      any    `json:"value"`
+	Changed    bool   `json:"changed"`
+	Source     string `json:"source,omitempty"`
+	Provenance string `json:"provenance,omitempty"`
+}
+
+// Document is the serialization format of an option dump.
+type Document struct {
+	Flags []Entry `json:"flags"`
+}
+
+// Collect provides the effective values for all flags of a pflag.FlagSet
+// ordered by the Options objects of the given OptionSet
+// responsible for those flags.
+// Flags of dump Options and flag aliases are omitted.
+func Collect(opts flagutils.OptionSetProvider, fs *pflag.FlagSet) *Document {
+	doc := &Document{Flags: []Entry{}}
+	done := set.New[string]()
+
+	list := flagutils.Filter[flagutils.Options](opts)
+	var skip []flagutils.Options
+	for _, o := range list {
+		if _, ok := flagutils.Unwrap(o).(*Options); ok {
+			skip = append(skip, o)
+		}
+	}
+
+	add := func(f *pflag.Flag) {
+		if done.Has(f.Name) || flagutils.FlagAliasTarget(f) != nil {
+			return
+		}
+		for _, o := range skip {
+			if flagutils.IsFlagOf(f, o) {
+				return
+			}
+		}
+		done.Add(f.Name)
+		doc.Flags = append(doc.Flags, Entry{
+			Name:       f.Name,
+			Type:       f.Value.Type(),
+			Value:      Value(f),
+			Changed:    f.Changed,
+			Source:     flagutils.GetFlagOptions(f),
+			Provenance: flagutils.FlagOrigin(f).String(),
+		})
+	}
+
+	for _, o := range list {
+		fs.VisitAll(func(f *pflag.Flag) {
+			if flagutils.IsFlagOf(f, o) {
+				add(f)
+			}
+		})
+	}
+	fs.VisitAll(add)
+	return doc
+}
+
+// Value provides the effective value of a flag.
+// Map values are provided as list of assignments (see
+// flagutils.FlagAssignments), slice values as string list and
+// all other values as string. The values of secret flags
+// (see flagutils.IsSecretFlag) are omitted.
+func Value(f *pflag.Flag) any {
+	if flagutils.IsSecretFlag(f) {
+		return nil
+	}
+	if list, ok := flagutils.FlagAssignments(f); ok {
+		return append([]string{}, list...)
+	}
+	if v, ok := f.Value.(pflag.SliceValue); ok {
+		return append([]string{}, v.GetSlice()...)
+	}
+	return f.Value.String()
+}
+
+// Apply sets the changed flag values of a Document for the given pflag.FlagSet.
+// Flags already changed are only set if override is true.
+// Secret flags are never set.
+// The origin of the values is recorded as config file with the
+// optionally given source.
+func Apply(fs *pflag.FlagSet, doc *Document, override bool, source ...string) error {
+	origin := flagutils.NewOrigin(flagutils.ORIGIN_CONFIG, source...)
+	for _, e := range doc.Flags {
+		f := fs.Lookup(e.Name)
+		if f == nil {
+			return fmt.Errorf("unknown flag %q", e.Name)
+		}
+		if !e.Changed || (f.Changed && !override) || flagutils.IsSecretFlag(f) {
+			continue
+		}
+		if err := setValue(fs, f, e.Value); err != nil {
+			return errors.Wrapf(err, "flag %q", e.Name)
+		}
+		flagutils.SetOrigin(f, origin)
+	}
+	return nil
+}
+
+func setValue(fs *pflag.FlagSet, f *pflag.Flag, value any) error {
+	if _, ok := flagutils.FlagAssignments(f); ok {
+		list, err := stringList(value)
+		if err != nil {
+			return err
+		}
+		for _, a := range list {
+			if err := fs.Set(f.Name, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch v := f.Value.(type) {
+	case pflag.SliceValue:
+		list, err := stringList(value)
+		if err != nil {
+			return err
+		}
+		if err := v.Replace(list); err != nil {
+			return err
+		}
+		f.Changed = true
+	default:
+		if _, ok := value.([]any); ok {
+			return fmt.Errorf("list value not possible for type %s", f.Value.Type())
+		}
+		return fs.Set(f.Name, toString(value))
+	}
+	return nil
+}
+
+func stringList(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []any:
+		list := make([]string, len(v))
+		for i, e := range v {
+			list[i] = toString(e)
+		}
+		return list, nil
+	case map[string]any:
+		return nil, fmt.Errorf("map value not possible")
+	default:
+		return []string{toString(v)}, nil
+	}
+}
+
+func toString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// Parse parses a YAML or JSON document.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrapf(err, "invalid option dump")
+	}
+	return &doc, nil
+}
+
+// Format serializes a Document using the given format.
+func Format(doc *Document, format string) ([]byte, error) {
+	switch format {
+	case FORMAT_YAML:
+		return yaml.Marshal(doc)
+	case FORMAT_JSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("invalid dump format %q (possible formats are %s and %s)", format, FORMAT_YAML, FORMAT_JSON)
+	}
+}
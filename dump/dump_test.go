@@ -0,0 +1,150 @@
+package dump_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/dump"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/pflags"
+	"github.com/mandelsoft/flagutils/utils/out"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type LabelOptions struct {
+	labels map[string]string
+}
+
+func (o *LabelOptions) AddFlags(fs *pflag.FlagSet) {
+	pflags.StringToStringVarP(fs, &o.labels, "label", "l", nil, "labels")
+}
+
+func setup() (flagutils.ExtendableOptionSet, *pflag.FlagSet) {
+	opts := flagutils.NewOptionSet(
+		dump.New(),
+		flagutils.NewOptionSet(tableoutput.New()),
+		flagutils.NewDefaultOptionsRef[*LabelOptions](),
+		flagutils.NewPrefixedOptions("target", parallel.New()),
+	)
+	MustBeSuccessful(flagutils.Prepare(context.Background(), opts, nil))
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(fs)
+	return opts, fs
+}
+
+func entry(doc *dump.Document, name string) dump.Entry {
+	for _, e := range doc.Flags {
+		if e.Name == name {
+			return e
+		}
+	}
+	return dump.Entry{}
+}
+
+var _ = Describe("dump", func() {
+	var ctx context.Context
+	var buf *bytes.Buffer
+
+	BeforeEach(func() {
+		buf = bytes.NewBuffer(nil)
+		ctx = out.With(context.Background(), out.New(os.Stdout, buf))
+	})
+
+	It("dumps effective values", func() {
+		opts, fs := setup()
+		MustBeSuccessful(fs.Parse([]string{"--dump-options", "--columns", "name,size", "-l", "a=b", "-l", "c=d,e"}))
+
+		doc := Must(dump.From(opts).Dump(opts))
+		Expect(doc.Flags).To(Equal([]dump.Entry{
+			{Name: "columns", Type: "stringSlice", Value: []string{"name", "size"}, Changed: true, Source: "*tableoutput.Options", Provenance: "command line"},
+			{Name: "label", Type: "<name>=<value>", Value: []string{"a=b", `"c=d,e"`}, Changed: true, Source: "*dump_test.LabelOptions", Provenance: "command line"},
+			{Name: "target-parallel", Type: "int", Value: "0", Changed: false, Source: "*parallel.Options[target]", Provenance: "default"},
+		}))
+
+		MustBeSuccessful(flagutils.Validate(ctx, opts, nil))
+		Expect(buf.String()).To(BeEmpty())
+		Must(flagutils.RunContext(ctx, opts))
+		Expect(buf.String()).To(ContainSubstring("name: columns\n"))
+	})
+
+	It("replays a dump", func() {
+		opts, fs := setup()
+		MustBeSuccessful(fs.Parse([]string{"--dump-options=json", "--columns", "name,size", "-l", "a=b", "-l", "c=d,e", "--target-parallel=2"}))
+		MustBeSuccessful(flagutils.Validate(ctx, opts, nil))
+		Must(flagutils.RunContext(ctx, opts))
+
+		file := filepath.Join(GinkgoT().TempDir(), "dump.json")
+		MustBeSuccessful(os.WriteFile(file, buf.Bytes(), 0o600))
+
+		opts, fs = setup()
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--target-parallel=3", "--load-options", file}))
+		doc := Must(dump.From(opts).Dump(opts))
+		Expect(doc.Flags).To(Equal([]dump.Entry{
+			{Name: "columns", Type: "stringSlice", Value: []string{"name", "size"}, Changed: true, Source: "*tableoutput.Options", Provenance: "config file " + file},
+			{Name: "label", Type: "<name>=<value>", Value: []string{"a=b", `"c=d,e"`}, Changed: true, Source: "*dump_test.LabelOptions", Provenance: "config file " + file},
+			{Name: "target-parallel", Type: "int", Value: "3", Changed: true, Source: "*parallel.Options[target]", Provenance: "command line"},
+		}))
+	})
+
+	Context("core map flags", func() {
+		var strings map[string]string
+		var ints map[string]int
+		var ints64 map[string]int64
+
+		flags := func() *pflag.FlagSet {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.StringToStringVar(&strings, "strings", nil, "")
+			fs.StringToIntVar(&ints, "ints", nil, "")
+			fs.StringToInt64Var(&ints64, "ints64", nil, "")
+			return fs
+		}
+
+		It("round-trips stringToString", func() {
+			fs := flags()
+			MustBeSuccessful(fs.Parse([]string{"--strings", `"a=x,y"`, "--strings", "b=2"}))
+			doc := dump.Collect(flagutils.NewOptionSet(), fs)
+			Expect(entry(doc, "strings").Value).To(Equal([]string{`"a=x,y"`, "b=2"}))
+
+			doc = Must(dump.Parse(Must(dump.Format(doc, dump.FORMAT_YAML))))
+			MustBeSuccessful(dump.Apply(flags(), doc, false))
+			Expect(strings).To(Equal(map[string]string{"a": "x,y", "b": "2"}))
+		})
+
+		It("round-trips stringToInt", func() {
+			fs := flags()
+			MustBeSuccessful(fs.Parse([]string{"--ints", "a=1,b=2"}))
+			doc := dump.Collect(flagutils.NewOptionSet(), fs)
+			Expect(entry(doc, "ints").Value).To(Equal([]string{"a=1", "b=2"}))
+
+			doc = Must(dump.Parse(Must(dump.Format(doc, dump.FORMAT_JSON))))
+			MustBeSuccessful(dump.Apply(flags(), doc, false))
+			Expect(ints).To(Equal(map[string]int{"a": 1, "b": 2}))
+		})
+
+		It("round-trips stringToInt64", func() {
+			fs := flags()
+			MustBeSuccessful(fs.Parse([]string{"--ints64", "a=1", "--ints64", "b=2"}))
+			doc := dump.Collect(flagutils.NewOptionSet(), fs)
+			Expect(entry(doc, "ints64").Value).To(Equal([]string{"a=1", "b=2"}))
+
+			doc = Must(dump.Parse(Must(dump.Format(doc, dump.FORMAT_YAML))))
+			MustBeSuccessful(dump.Apply(flags(), doc, false))
+			Expect(ints64).To(Equal(map[string]int64{"a": 1, "b": 2}))
+		})
+	})
+
+	It("rejects invalid format", func() {
+		opts, fs := setup()
+		MustBeSuccessful(fs.Parse([]string{"--dump-options=xml"}))
+		Expect(flagutils.Validate(ctx, opts, nil)).To(MatchError(ContainSubstring("invalid dump format")))
+	})
+})
@@ -0,0 +1,154 @@
+package dump
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+type Options struct {
+	dump flagutils.SimpleOption[string, *Options]
+	load flagutils.SimpleOption[string, *Options]
+
+	fs   *pflag.FlagSet
+	data []byte
+}
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+var (
+	_ flagutils.Options         = (*Options)(nil)
+	_ flagutils.Validatable     = (*Options)(nil)
+	_ flagutils.ContextProvider = (*Options)(nil)
+	_ flagutils.FlagSetBinder   = (*Options)(nil)
+)
+
+func New() *Options {
+	o := &Options{}
+	o.dump = flagutils.NewSimpleOption[string](o, "", "dump-options", "", "dump effective option values on stderr (yaml or json)")
+	o.load = flagutils.NewSimpleOptionWithSetter[string](o, o.loadVarP, "", "load-options", "", "load option values from a dump file")
+	return o
+}
+
+func (o *Options) WithDumpNames(long, short string) *Options {
+	return o.dump.WithNames(long, short)
+}
+
+func (o *Options) WithDumpDescription(s string) *Options {
+	return o.dump.WithDescription(s)
+}
+
+func (o *Options) WithLoadNames(long, short string) *Options {
+	return o.load.WithNames(long, short)
+}
+
+func (o *Options) WithLoadDescription(s string) *Options {
+	return o.load.WithDescription(s)
+}
+
+// GetFormat provides the requested dump format.
+// It is empty if no dump is requested.
+func (o *Options) GetFormat() string {
+	return o.dump.Value()
+}
+
+// GetLoaded provides the path of the loaded dump file, if given.
+func (o *Options) GetLoaded() string {
+	return o.load.Value()
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.fs = fs
+	o.dump.AddFlags(fs)
+	if long, _ := o.dump.GetNames(); long != "" {
+		if f := fs.Lookup(long); f != nil {
+			f.NoOptDefVal = FORMAT_YAML
+		}
+	}
+	o.load.AddFlags(fs)
+}
+
+// BindFlagSet sets the flag set used to dump and load option values.
+func (o *Options) BindFlagSet(fs *pflag.FlagSet) {
+	o.fs = fs
+}
+
+// Dump provides the dump document for the given OptionSet.
+func (o *Options) Dump(opts flagutils.OptionSetProvider) (*Document, error) {
+	if o.fs == nil {
+		return nil, fmt.Errorf("dump options not added to a flag set")
+	}
+	return Collect(opts, o.fs), nil
+}
+
+// Validate prepares the requested dump. Because the validation may be
+// repeated after interactively corrected values, it is written not
+// before the run context is provided.
+func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flagutils.ValidationSet) error {
+	o.data = nil
+	format := o.GetFormat()
+	if format == "" {
+		return nil
+	}
+	doc, err := o.Dump(opts)
+	if err != nil {
+		return err
+	}
+	o.data, err = Format(doc, format)
+	return err
+}
+
+// ProvideContext writes the dump prepared by the validation
+// to the error output of the output context.
+func (o *Options) ProvideContext(ctx context.Context, opts flagutils.OptionSet) (context.Context, error) {
+	if o.data != nil {
+		if _, err := out.ErrWrite(ctx, o.data); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+func (o *Options) loadVarP(fs *pflag.FlagSet, p *string, name, shorthand string, value string, usage string) {
+	*p = value
+	fs.VarP(&loadValue{options: o, value: p}, name, shorthand, usage)
+}
+
+// loadValue applies a dump file when the flag is set.
+// Flags already given before are kept, flags given afterward
+// override the loaded values.
+type loadValue struct {
+	options *Options
+	value   *string
+}
+
+func (l *loadValue) Set(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	if err := Apply(l.options.fs, doc, false, path); err != nil {
+		return err
+	}
+	*l.value = path
+	return nil
+}
+
+func (l *loadValue) Type() string {
+	return "filepath"
+}
+
+func (l *loadValue) String() string {
+	return *l.value
+}
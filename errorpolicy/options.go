@@ -0,0 +1,84 @@
+package errorpolicy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+// Options is the --fail-on-error option describing the exit status
+// used if processed elements report an error (see output.ErrorProvider).
+// Without a value the status is flagutils.EXIT_FAILURE. The status 0
+// (the default) just reports the failed elements.
+// Because the value is optional, an explicit status must be given
+// as --fail-on-error=<status>. A separate argument is not
+// taken as value, but as positional argument.
+// It provides the Reporter shared by all outputs of a run.
+type Options struct {
+	flagutils.SimpleOption[int, *Options]
+	reporter *Reporter
+}
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+var (
+	_ flagutils.Options     = (*Options)(nil)
+	_ flagutils.Validatable = (*Options)(nil)
+)
+
+func New() *Options {
+	o := &Options{}
+	o.SimpleOption = flagutils.NewSimpleOption(o, 0, "fail-on-error", "", "exit status used for failed elements (given as =<status>)")
+	return o
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.SimpleOption.AddFlags(fs)
+	if long, _ := o.GetNames(); long != "" {
+		if f := fs.Lookup(long); f != nil {
+			f.NoOptDefVal = strconv.Itoa(flagutils.EXIT_FAILURE)
+		}
+	}
+}
+
+func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flagutils.ValidationSet) error {
+	s := o.Value()
+	if s < 0 || s > 125 {
+		o.reporter = nil
+		return fmt.Errorf("invalid exit status %d", s)
+	}
+	o.reporter = NewReporter(s)
+	return nil
+}
+
+// GetReporter provides the Reporter used for the
+// outputs of a run.
+func (o *Options) GetReporter() *Reporter {
+	return o.reporter
+}
+
+// Status provides the exit status for failed elements
+// requested by an option set. 0 means no failure.
+func Status(opts flagutils.OptionSetProvider) int {
+	o := From(opts)
+	if o == nil {
+		return 0
+	}
+	return o.Value()
+}
+
+// GetReporter provides the Reporter of an option set shared by
+// all outputs of a run, or nil if the set has no error policy option.
+func GetReporter(opts flagutils.OptionSetProvider) *Reporter {
+	o := From(opts)
+	if o == nil {
+		return nil
+	}
+	return o.GetReporter()
+}
@@ -0,0 +1,58 @@
+package errorpolicy_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/errorpolicy"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+)
+
+var _ = Describe("error policy options", func() {
+	var set flagutils.OptionSet
+
+	BeforeEach(func() {
+		set = flagutils.NewOptionSet(errorpolicy.New())
+	})
+
+	It("just reports errors by default", func() {
+		r := flagutilstest.Run(context.Background(), set, nil)
+		Expect(r.Error()).To(Succeed())
+		Expect(errorpolicy.Status(set)).To(Equal(0))
+		Expect(errorpolicy.GetReporter(set)).NotTo(BeNil())
+	})
+
+	It("uses the default failure status without value", func() {
+		r := flagutilstest.Run(context.Background(), set, nil, "--fail-on-error")
+		Expect(r.Error()).To(Succeed())
+		Expect(errorpolicy.Status(set)).To(Equal(flagutils.EXIT_FAILURE))
+	})
+
+	It("uses a given status", func() {
+		r := flagutilstest.Run(context.Background(), set, nil, "--fail-on-error=5")
+		Expect(r.Error()).To(Succeed())
+		Expect(errorpolicy.Status(set)).To(Equal(5))
+	})
+
+	It("does not take a separate argument as status", func() {
+		r := flagutilstest.Run(context.Background(), set, nil, "--fail-on-error", "5")
+		Expect(r.Error()).To(Succeed())
+		Expect(errorpolicy.Status(set)).To(Equal(flagutils.EXIT_FAILURE))
+		Expect(r.Args).To(Equal([]string{"5"}))
+	})
+
+	It("rejects invalid status", func() {
+		r := flagutilstest.Run(context.Background(), set, nil, "--fail-on-error=126")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring("invalid exit status 126")))
+		Expect(errorpolicy.GetReporter(set)).To(BeNil())
+	})
+
+	It("provides no policy without option", func() {
+		set = flagutils.NewOptionSet()
+		Expect(errorpolicy.Status(set)).To(Equal(0))
+		Expect(errorpolicy.GetReporter(set)).To(BeNil())
+	})
+})
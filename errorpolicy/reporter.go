@@ -0,0 +1,139 @@
+package errorpolicy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mandelsoft/streaming/chain"
+
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// ErrorProvider is implemented by elements describing
+// a failure. Such errors are reported by the outputs
+// (see Reporter).
+type ErrorProvider interface {
+	GetError() error
+}
+
+// GetError provides the error of an element, if
+// it implements the ErrorProvider interface.
+func GetError(e any) error {
+	if p, ok := e.(ErrorProvider); ok {
+		return p.GetError()
+	}
+	return nil
+}
+
+// ElementsError is the error provided by an output if elements
+// failed and the exit status policy requests a failure.
+// It provides the exit status by its ExitCode method.
+type ElementsError struct {
+	Errors []error
+	Status int
+}
+
+func (e *ElementsError) Error() string {
+	if len(e.Errors) == 1 {
+		return "1 element failed"
+	}
+	return fmt.Sprintf("%d elements failed", len(e.Errors))
+}
+
+func (e *ElementsError) Unwrap() []error {
+	return e.Errors
+}
+
+func (e *ElementsError) ExitCode() int {
+	return e.Status
+}
+
+// Reporter collects the errors of processed elements.
+// They are reported on stderr and, if a non-zero exit status
+// is configured, mapped to an ElementsError.
+// If the same elements are processed several times (for example,
+// by multiple output modes), every processing is started with
+// NextPass. The errors of all passes are reported, but errors
+// already recorded by a previous pass (with the same message) are
+// reported only once.
+// All methods can be called on a nil Reporter.
+type Reporter struct {
+	lock     sync.Mutex
+	status   int
+	errors   []error
+	reported []error
+}
+
+func NewReporter(status int) *Reporter {
+	return &Reporter{status: status}
+}
+
+// Check records the error of an element.
+func (r *Reporter) Check(e any) {
+	if r == nil {
+		return
+	}
+	if err := GetError(e); err != nil {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.errors = append(r.errors, err)
+	}
+}
+
+// NextPass starts another processing of the same elements.
+func (r *Reporter) NextPass() {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.keep()
+}
+
+// keep adds the errors of the actual pass not already
+// recorded by previous passes to the reported errors.
+func (r *Reporter) keep() {
+	known := map[string]int{}
+	for _, err := range r.reported {
+		known[err.Error()]++
+	}
+	for _, err := range r.errors {
+		if known[err.Error()] > 0 {
+			known[err.Error()]--
+			continue
+		}
+		r.reported = append(r.reported, err)
+	}
+	r.errors = nil
+}
+
+// Report reports and resets the recorded errors. It provides
+// an ElementsError, if errors are found and a failure is requested.
+func (r *Reporter) Report(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	r.keep()
+	list := r.reported
+	r.reported = nil
+	r.lock.Unlock()
+
+	for _, err := range list {
+		out.ErrPrintf(ctx, "Error: %s\n", err)
+	}
+	if len(list) == 0 || r.status == 0 {
+		return nil
+	}
+	return &ElementsError{list, r.status}
+}
+
+// AddErrorChain adds a step to a chain recording the
+// errors of the elements.
+func AddErrorChain[I, O any](r *Reporter, c chain.Chain[I, O]) chain.Chain[I, O] {
+	return chain.AddMap[O](c, func(e O) O {
+		r.Check(e)
+		return e
+	})
+}
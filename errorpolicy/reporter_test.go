@@ -0,0 +1,89 @@
+package errorpolicy_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/errorpolicy"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+type element struct {
+	err string
+}
+
+func (e *element) GetError() error {
+	if e.err == "" {
+		return nil
+	}
+	return fmt.Errorf("%s", e.err)
+}
+
+var _ = Describe("reporter", func() {
+	var ctx context.Context
+	var stderr *bytes.Buffer
+
+	BeforeEach(func() {
+		stderr = &bytes.Buffer{}
+		ctx = out.With(context.Background(), out.New(&bytes.Buffer{}, stderr))
+	})
+
+	check := func(r *errorpolicy.Reporter, elems ...*element) {
+		for _, e := range elems {
+			r.Check(e)
+		}
+	}
+
+	It("reports errors without failure", func() {
+		r := errorpolicy.NewReporter(0)
+		check(r, &element{"a failed"}, &element{}, &element{"b failed"})
+		Expect(r.Report(ctx)).To(Succeed())
+		Expect(stderr.String()).To(Equal("Error: a failed\nError: b failed\n"))
+	})
+
+	It("maps errors to the exit status", func() {
+		r := errorpolicy.NewReporter(3)
+		check(r, &element{"a failed"}, &element{"b failed"})
+		err := r.Report(ctx)
+		Expect(err).To(MatchError("2 elements failed"))
+		Expect(flagutils.ExitCode(err)).To(Equal(3))
+		Expect(flagutils.ExitCode(&flagutils.LifecycleError{Phase: flagutils.PHASE_RUN, Err: err})).To(Equal(3))
+	})
+
+	It("succeeds without errors", func() {
+		r := errorpolicy.NewReporter(3)
+		check(r, &element{})
+		Expect(r.Report(ctx)).To(Succeed())
+		Expect(stderr.String()).To(Equal(""))
+	})
+
+	It("resets the errors after reporting", func() {
+		r := errorpolicy.NewReporter(3)
+		check(r, &element{"a failed"})
+		Expect(r.Report(ctx)).To(MatchError("1 element failed"))
+		Expect(r.Report(ctx)).To(Succeed())
+	})
+
+	It("reports the errors of every pass once", func() {
+		r := errorpolicy.NewReporter(1)
+		check(r, &element{"a failed"}, &element{"a failed"}, &element{"b failed"})
+		r.NextPass()
+		check(r, &element{"a failed"}, &element{"c failed"})
+		r.NextPass()
+		check(r, &element{"b failed"}, &element{"d failed"})
+		Expect(r.Report(ctx)).To(MatchError("5 elements failed"))
+		Expect(stderr.String()).To(Equal("Error: a failed\nError: a failed\nError: b failed\nError: c failed\nError: d failed\n"))
+	})
+
+	It("handles nil reporters", func() {
+		var r *errorpolicy.Reporter
+		check(r, &element{"a failed"})
+		r.NextPass()
+		Expect(r.Report(ctx)).To(Succeed())
+	})
+})
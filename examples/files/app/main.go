@@ -4,16 +4,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/progress"
 	"os"
 
 	"github.com/spf13/pflag"
 
 	"github.com/mandelsoft/flagutils"
 	"github.com/mandelsoft/flagutils/closure"
+	"github.com/mandelsoft/flagutils/errorpolicy"
 	"github.com/mandelsoft/flagutils/examples/files/files"
 	"github.com/mandelsoft/flagutils/output"
 	"github.com/mandelsoft/flagutils/output/tableoutput"
 	"github.com/mandelsoft/flagutils/sort"
+	"github.com/mandelsoft/flagutils/summary"
 )
 
 func Error(msg string, args ...interface{}) {
@@ -27,8 +30,11 @@ func main() {
 	opts.Add(
 		files.New(),
 		parallel.New(),
+		progress.New(true),
 		closure.NewByFactory[*files.Element](files.ClosureFactory),
 		sort.New(),
+		summary.New(),
+		errorpolicy.New(),
 		tableoutput.New(),
 		output.New(files.OutputsFactory),
 	)
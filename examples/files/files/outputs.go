@@ -10,12 +10,15 @@ import (
 	"github.com/mandelsoft/flagutils/output/treeoutput/topo"
 )
 
-var OutputsFactory = output.NewOutputsFactory[*Element]().
+// Summary declares the aggregated fields shown with --summary.
+var Summary = []output.SummaryField{output.Total("SIZE")}
+
+var OutputsFactory = output.AddManifestOutputs(output.NewOutputsFactory[*Element]().
 	Add("", tableoutput.NewOutputFactory[*Element](map_standard, "NAME", "ERROR")).
-	Add("wide", tableoutput.NewOutputFactory[*Element](map_wide, "MODE", "NAME", "-SIZE", "ERROR")).
+	Add("wide", tableoutput.NewOutputFactory[*Element](map_wide, "MODE", "NAME", "-SIZE", "ERROR").WithSummary(Summary...)).
 	Add("test", tableoutput.NewOutputFactoryByProvider[*Element, output.ExtendableFieldProvider](tableoutput.NewTopoHierarchMappingProvider[string, *Element, output.ExtendableFieldProvider]("PATH", string(os.PathSeparator), map_wide, "MODE", "NAME", "-SIZE", "ERROR"))).
-	Add("tree", treeoutput.NewOutputFactory[string, string, *Element](treeoutput.WithHeader[string](""), topo.NewStringIdComparerFactory[string, *Element](), map_tree, "MODE", "NAME", "-SIZE", "ERROR")).
-	AddManifestOutputs()
+	Add("tree", treeoutput.NewOutputFactory[string, string, *Element](treeoutput.WithHeader[string](""), topo.NewStringIdComparerFactory[string, *Element](), map_tree, "MODE", "NAME", "-SIZE", "ERROR").WithSummary(Summary...)),
+	Summary...)
 
 func map_standard(e *Element) output.FieldProvider {
 	errstr := ""
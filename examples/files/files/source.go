@@ -20,6 +20,7 @@ type Element struct {
 }
 
 var _ history.HistoryProvider[string] = (*Element)(nil)
+var _ output.ErrorProvider = (*Element)(nil)
 var _ tree.Object[string] = (*Element)(nil)
 
 func NewElement(name string, hist history.History[string]) *Element {
@@ -35,6 +36,10 @@ func (e *Element) IsNode() *string {
 	return nil
 }
 
+func (e *Element) GetError() error {
+	return e.Error
+}
+
 func (e *Element) GetPath() string {
 	return strings.Join(e.GetHierarchy(), string(os.PathSeparator))
 }
@@ -0,0 +1,106 @@
+package flagutils
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// FlagOptionsAnnotation is the pflag.Flag annotation used to
+// record the Options object responsible for a flag.
+// It is maintained by the AddFlags method of DefaultOptionSet.
+// The values are the description of the object (see DescribeOptions)
+// and an identity of the object instance (see IsFlagOf).
+const FlagOptionsAnnotation = "flagutils-options"
+
+// OptionsDescriber may be implemented by Options objects
+// to provide a description used for diagnostic output.
+type OptionsDescriber interface {
+	DescribeOptions() string
+}
+
+// DescribeOptions provides a description of an Options object
+// used for diagnostic output. By default, this is the
+// go type of the (unwrapped) object.
+func DescribeOptions(o Options) string {
+	if d, ok := o.(OptionsDescriber); ok {
+		return d.DescribeOptions()
+	}
+	if u := Unwrap(o); u != o && u != nil {
+		return DescribeOptions(u)
+	}
+	return fmt.Sprintf("%T", o)
+}
+
+// GetFlagOptions provides the description of the Options object
+// responsible for the given flag. It is only available if the flag
+// has been added by a DefaultOptionSet.
+func GetFlagOptions(f *pflag.Flag) string {
+	if f == nil || f.Annotations == nil {
+		return ""
+	}
+	if list := f.Annotations[FlagOptionsAnnotation]; len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+// IsFlagOf reports whether the given flag has been added by the given
+// Options object. Flags are assigned to Options object instances,
+// so that multiple instances of the same Options type (for example,
+// by PrefixedOptions) are distinguished. Wrapping objects
+// (like PrefixedOptions or OptionsRef) are identified with the
+// wrapped object.
+func IsFlagOf(f *pflag.Flag, o Options) bool {
+	if f == nil || f.Annotations == nil {
+		return false
+	}
+	list := f.Annotations[FlagOptionsAnnotation]
+	if len(list) == 0 {
+		return false
+	}
+	if id := optionsID(o); id != "" && len(list) > 1 && list[1] != "" {
+		return list[1] == id
+	}
+	return list[0] == DescribeOptions(o)
+}
+
+// optionsID provides the identity of an Options object instance
+// recorded by the FlagOptionsAnnotation. It is only available for
+// pointer types.
+func optionsID(o Options) string {
+	v := reflect.ValueOf(Unwrap(o))
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return ""
+	}
+	return fmt.Sprintf("%x", v.Pointer())
+}
+
+// setFlagOptions annotates a flag with the responsible Options object.
+// The identity of an already annotated flag is kept.
+func setFlagOptions(f *pflag.Flag, desc string, o Options) {
+	id := optionsID(o)
+	if f.Annotations != nil {
+		if list := f.Annotations[FlagOptionsAnnotation]; len(list) > 1 && list[1] != "" {
+			id = list[1]
+		}
+	}
+	annotateFlag(f, FlagOptionsAnnotation, desc, id)
+}
+
+// annotateFlags annotates all flags of a flag set
+// with the given Options object.
+// Flags already annotated by a wrapped Options object are kept.
+func annotateFlags(fs *pflag.FlagSet, o Options) {
+	desc := ""
+	fs.VisitAll(func(f *pflag.Flag) {
+		if GetFlagOptions(f) != "" {
+			return
+		}
+		if desc == "" {
+			desc = DescribeOptions(o)
+		}
+		setFlagOptions(f, desc, o)
+	})
+}
@@ -48,6 +48,10 @@ type Options interface {
 	GetValue(name string) (interface{}, bool)
 	Changed(names ...string) bool
 
+	// Args renders the changed options back to
+	// command line arguments (see flagutils.FlagArgs).
+	Args() []string
+
 	FilterBy(Filter) Options
 }
 
@@ -136,6 +140,13 @@ func (o *configOptions) Changed(names ...string) bool {
 	return false
 }
 
+func (o *configOptions) Args() []string {
+	if o.flags == nil {
+		return nil
+	}
+	return flagutils.FlagArgs(o.flags, o.Names()...)
+}
+
 func (o *configOptions) FilterBy(filter Filter) Options {
 	if filter == nil {
 		return o
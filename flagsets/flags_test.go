@@ -79,3 +79,20 @@ var _ = Describe("type set", func() {
 		Expect(v.(string)).To(Equal("other string"))
 	})
 })
+
+var _ = Describe("argument rendering", func() {
+	It("renders changed options", func() {
+		set := flagsets.NewOptionTypeSet("first")
+		set.AddOptionType(flagsets.NewStringOptionType("string", "a test string"))
+		set.AddOptionType(flagsets.NewStringOptionType("other", "another test string"))
+		set.AddOptionType(flagsets.NewStringOptionType("unused", "an unused string"))
+
+		flags := pflag.NewFlagSet("flags", pflag.ContinueOnError)
+		flags.String("foreign", "", "foreign flag")
+		opts := set.CreateOptions()
+		opts.AddFlags(flags)
+
+		Expect(flags.Parse([]string{"--string=string", "--other=other string", "--foreign=x"})).To(Succeed())
+		Expect(opts.Args()).To(Equal([]string{"--other=other string", "--string=string"}))
+	})
+})
@@ -2,10 +2,14 @@ package flagsets
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/general"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
 )
 
 // ConfigProvider is able to create
@@ -109,6 +113,36 @@ func (p *typedConfigProvider) IsExplicitlySelected(opts Options) bool {
 	return opts.Changed(p.typeOptionType.GetName(), p.GetPlainOptionType().GetName())
 }
 
+// CreateOptions creates the Options offering the known type names
+// as choices for the type option used by interactive input.
+func (p *typedConfigProvider) CreateOptions() Options {
+	return &typeChoiceOptions{p._TypedOptionSetConfigProvider.CreateOptions(), p}
+}
+
+func (p *typedConfigProvider) typeNames() []string {
+	var names []string
+	for _, s := range p.OptionTypeSets() {
+		names = append(names, s.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// _Options is a private type used for private field embedding.
+type _Options = Options
+
+type typeChoiceOptions struct {
+	_Options
+	provider *typedConfigProvider
+}
+
+func (o *typeChoiceOptions) AddFlags(fs *pflag.FlagSet) {
+	o._Options.AddFlags(fs)
+	if f := fs.Lookup(o.provider.typeOptionType.GetName()); f != nil {
+		flagutils.SetFlagChoices(f, o.provider.typeNames()...)
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 // TypeNameProviderFromOptions offers a function extractiong
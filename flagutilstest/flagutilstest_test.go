@@ -0,0 +1,102 @@
+package flagutilstest_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/utils/out"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type Finalizer struct {
+	flagutils.NoOptions
+	err error
+}
+
+func (f *Finalizer) Finalize(ctx context.Context, opts flagutils.OptionSet, v flagutils.FinalizationSet) error {
+	out.ErrPrintf(ctx, "finalized\n")
+	return f.err
+}
+
+var table = flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+	tableoutput.FormatTable(ctx, "", [][]string{{"NAME", "DEGREE"}, {"alice", fmt.Sprint(parallel.From(opts).Value())}})
+	return nil
+})
+
+var _ = Describe("test harness", func() {
+	It("runs the lifecycle", func() {
+		r := flagutilstest.Run(nil, flagutils.NewOptionSet(parallel.New(), &Finalizer{}), table, "-p", "3", "arg")
+		MustBeSuccessful(r.Error())
+		Expect(r.Args).To(Equal([]string{"arg"}))
+		Expect(parallel.From(r.Options).Value()).To(Equal(3))
+		Expect(flagutils.GetFlagSet(r.Options)).To(BeIdenticalTo(r.FlagSet))
+		Expect(flagutils.Provenance(r.Options, "parallel").Kind).To(Equal(flagutils.ORIGIN_COMMANDLINE))
+		Expect(r.Stdout).To(flagutilstest.EqualTable(`
+NAME DEGREE
+alice 3
+`))
+		Expect(r.Stderr).To(Equal("finalized\n"))
+	})
+
+	It("reports validation errors", func() {
+		r := flagutilstest.Run(nil, flagutils.NewOptionSet(parallel.New(), &Finalizer{}), table, "-p", "-1")
+		Expect(r.ValidationError).To(MatchError("invalid degree of parallelism: -1 (--parallel from command line)"))
+		Expect(r.Stdout).To(Equal(""))
+		Expect(r.Stderr).To(Equal("finalized\n"))
+	})
+
+	It("reports parse and finalize errors", func() {
+		r := flagutilstest.Run(nil, flagutils.NewOptionSet(parallel.New()), nil, "--unknown")
+		Expect(r.ParseError).To(MatchError("unknown flag: --unknown"))
+
+		r = flagutilstest.Run(nil, flagutils.NewOptionSet(&Finalizer{err: fmt.Errorf("cleanup failed")}), nil)
+		Expect(r.RunError).To(Succeed())
+		Expect(r.FinalizeError).To(MatchError("cleanup failed"))
+		Expect(r.Error()).To(MatchError("cleanup failed"))
+	})
+
+	It("reports flag collisions", func() {
+		r := flagutilstest.Run(nil, flagutils.NewOptionSet(parallel.New(), parallel.New()), nil)
+		Expect(r.PrepareError).To(MatchError(ContainSubstring("flag collisions: --parallel")))
+	})
+
+	It("uses the collision policy of the option set", func() {
+		set := flagutils.NewOptionSet(parallel.New(), parallel.New().WithNames("workers", "p"), flagutils.COLLISION_DROP_SHORTHANDS)
+		r := flagutilstest.Run(nil, set, nil, "-p", "2", "--workers", "3")
+		MustBeSuccessful(r.Error())
+		Expect(r.FlagSet.Lookup("workers").Shorthand).To(Equal(""))
+		Expect(flagutils.Filter[*flagutils.FlagSetRecorder](set)).To(BeEmpty())
+	})
+
+	Context("golden files", func() {
+		It("tolerates column alignment", func() {
+			Expect("NAME    DEGREE\nalice   3  \n").To(flagutilstest.MatchGolden("testdata/table.txt"))
+			Expect("  NAME DEGREE\nalice 3\n").NotTo(flagutilstest.MatchGolden("testdata/table.txt"))
+		})
+
+		It("reports differences", func() {
+			Expect(flagutilstest.CompareGolden("testdata/table.txt", "NAME DEGREE\nbob 3\n")).To(
+				MatchError(`golden file testdata/table.txt does not match: line 2: expected "alice 3", found "bob 3"`))
+		})
+
+		It("updates golden files", func() {
+			dir := Must(os.MkdirTemp("", "golden"))
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "sub", "out.txt")
+
+			os.Setenv(flagutilstest.UPDATE_GOLDEN, "true")
+			defer os.Unsetenv(flagutilstest.UPDATE_GOLDEN)
+			MustBeSuccessful(flagutilstest.CompareGolden(path, "a b\n"))
+			Expect(string(Must(os.ReadFile(path)))).To(Equal("a b\n"))
+		})
+	})
+})
@@ -0,0 +1,110 @@
+package flagutilstest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// UPDATE_GOLDEN is the environment variable used to request
+// updating golden files instead of comparing them.
+const UPDATE_GOLDEN = "FLAGUTILS_UPDATE_GOLDEN"
+
+// NormalizeTable normalizes a text for a comparison tolerating
+// different column alignments. Sequences of blanks inside a line are reduced
+// to a single blank, trailing blanks and leading and trailing empty lines are
+// removed. The indentation of lines is kept.
+func NormalizeTable(s string) string {
+	lines := strings.Split(strings.Trim(s, "\n"), "\n")
+	for i, l := range lines {
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		lines[i] = indent + strings.Join(strings.FieldsFunc(l, isBlank), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// EqualTable provides a matcher comparing a string with the expected one
+// ignoring differences in column alignment (see NormalizeTable).
+func EqualTable(exp string) types.GomegaMatcher {
+	return gomega.WithTransform(NormalizeTable, gomega.Equal(NormalizeTable(exp)))
+}
+
+// CompareGolden compares a text with the content of a golden file ignoring
+// differences in column alignment. If the environment variable UPDATE_GOLDEN
+// is set to true, the golden file is updated, instead.
+func CompareGolden(path string, actual string) error {
+	if os.Getenv(UPDATE_GOLDEN) == "true" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(actual), 0o644)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	exp := NormalizeTable(string(data))
+	act := NormalizeTable(actual)
+	if exp != act {
+		return fmt.Errorf("golden file %s does not match: %s", path, diff(exp, act))
+	}
+	return nil
+}
+
+func diff(exp, act string) string {
+	el := strings.Split(exp, "\n")
+	al := strings.Split(act, "\n")
+	for i := range el {
+		if i >= len(al) {
+			return fmt.Sprintf("missing line %d: %q", i+1, el[i])
+		}
+		if el[i] != al[i] {
+			return fmt.Sprintf("line %d: expected %q, found %q", i+1, el[i], al[i])
+		}
+	}
+	return fmt.Sprintf("additional line %d: %q", len(el)+1, al[len(el)])
+}
+
+// MatchGolden provides a matcher comparing a string or byte slice
+// with a golden file (see CompareGolden).
+func MatchGolden(path string) types.GomegaMatcher {
+	return &goldenMatcher{path: path}
+}
+
+type goldenMatcher struct {
+	path string
+	err  error
+}
+
+func (m *goldenMatcher) Match(actual interface{}) (bool, error) {
+	var s string
+	switch a := actual.(type) {
+	case string:
+		s = a
+	case []byte:
+		s = string(a)
+	case fmt.Stringer:
+		s = a.String()
+	default:
+		return false, fmt.Errorf("MatchGolden expects a string, byte slice or fmt.Stringer, but got %s", format.Object(actual, 1))
+	}
+	m.err = CompareGolden(m.path, s)
+	return m.err == nil, nil
+}
+
+func (m *goldenMatcher) FailureMessage(actual interface{}) string {
+	return m.err.Error()
+}
+
+func (m *goldenMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("expected text not to match golden file %s", m.path)
+}
@@ -0,0 +1,112 @@
+// Package flagutilstest provides helpers for testing option sets
+// and outputs based on the flagutils lifecycle.
+package flagutilstest
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// RunnerFunc is a function implementing flagutils.Runner.
+type RunnerFunc func(ctx context.Context, opts flagutils.OptionSet) error
+
+func (f RunnerFunc) Run(ctx context.Context, opts flagutils.OptionSet) error {
+	return f(ctx, opts)
+}
+
+// Result describes the outcome of a lifecycle execution by Run.
+type Result struct {
+	// Options is the executed option set. It contains the
+	// FlagSetRecorder used for the execution, so that flag related
+	// information, like flagutils.Provenance, is available.
+	Options flagutils.OptionSet
+	// FlagSet is the flag set used to parse the arguments.
+	FlagSet *pflag.FlagSet
+	// Args are the positional arguments left after parsing.
+	Args []string
+
+	Stdout string
+	Stderr string
+
+	PrepareError    error
+	ParseError      error
+	ValidationError error
+	RunError        error
+	FinalizeError   error
+}
+
+// Error provides all errors of the execution.
+func (r *Result) Error() error {
+	return errors.Join(r.PrepareError, r.ParseError, r.ValidationError, r.RunError, r.FinalizeError)
+}
+
+// Run executes the lifecycle of an OptionSet for the given arguments with
+// flagutils.ExecuteLifecycle, but captures the output of the output context
+// and the errors of all phases. The runner is optional. The given option set
+// is not modified.
+func Run(ctx context.Context, opts flagutils.OptionSetProvider, run flagutils.Runner, args ...string) *Result {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	ctx = out.With(ctx, out.New(stdout, stderr))
+
+	set := opts.AsOptionSet()
+	recorder := flagutils.GetFrom[*flagutils.FlagSetRecorder](set)
+	if recorder == nil {
+		recorder = flagutils.NewFlagSetRecorder()
+		set = flagutils.NewOptionSet(set, recorder)
+	}
+
+	r := &Result{Options: set}
+	obs := &observer{}
+	err := flagutils.ExecuteLifecycle(flagutils.WithLifecycleObserver(ctx, obs), "test", set, RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+		if run == nil {
+			return nil
+		}
+		return run.Run(ctx, opts)
+	}), args...)
+
+	r.FlagSet = recorder.FlagSet()
+	r.Stdout = stdout.String()
+	r.Stderr = stderr.String()
+
+	var lerr *flagutils.LifecycleError
+	if errors.As(err, &lerr) {
+		r.FinalizeError = lerr.Finalize
+		switch lerr.Phase {
+		case flagutils.PHASE_PREPARE:
+			r.PrepareError = lerr.Err
+		case flagutils.PHASE_PARSE:
+			r.ParseError = lerr.Err
+		case flagutils.PHASE_VALIDATE:
+			r.ValidationError = lerr.Err
+		case flagutils.PHASE_RUN:
+			r.RunError = lerr.Err
+		case flagutils.PHASE_FINALIZE:
+			r.FinalizeError = lerr.Err
+		}
+	}
+	if obs.parsed {
+		r.Args = r.FlagSet.Args()
+	}
+	return r
+}
+
+// observer captures whether the arguments have been parsed successfully.
+type observer struct {
+	parsed bool
+}
+
+func (o *observer) LifecycleEvent(ctx context.Context, e *flagutils.LifecycleEvent) {
+	if e.Object == nil && e.Kind == flagutils.EVENT_AFTER && e.Phase == flagutils.PHASE_PARSE {
+		o.parsed = e.Error == nil
+	}
+}
@@ -12,6 +12,7 @@ require (
 	github.com/onsi/gomega v1.38.2
 	github.com/spf13/pflag v1.0.10
 	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/term v0.36.0
 	sigs.k8s.io/yaml v1.6.0
 )
 
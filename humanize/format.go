@@ -0,0 +1,134 @@
+package humanize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mandelsoft/flagutils/output"
+)
+
+var (
+	siUnits  = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+	iecUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+)
+
+// BytesSI provides a byte size using SI units (1 kB = 1000 B).
+// The raw value is the number of bytes.
+func BytesSI(n int64) output.Value {
+	return output.Value{Raw: strconv.FormatInt(n, 10), Human: bytes(n, 1000, siUnits)}
+}
+
+// BytesIEC provides a byte size using IEC units (1 KiB = 1024 B).
+// The raw value is the number of bytes.
+func BytesIEC(n int64) output.Value {
+	return output.Value{Raw: strconv.FormatInt(n, 10), Human: bytes(n, 1024, iecUnits)}
+}
+
+func bytes(n int64, base float64, units []string) string {
+	v := float64(n)
+	i := 0
+	for (v >= base || v <= -base) && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d %s", n, units[0])
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0") + " " + units[i]
+}
+
+// Duration provides a duration shown by its two most significant units
+// (like 1h5m or 2d3h). The raw value is the number of seconds.
+func Duration(d time.Duration) output.Value {
+	return output.Value{Raw: seconds(d), Human: FormatDuration(d)}
+}
+
+// FormatDuration formats a duration by its two most significant units.
+// Durations below one second are shown with their standard representation.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	if d < time.Second {
+		return d.String()
+	}
+	units := []struct {
+		d    time.Duration
+		name string
+	}{{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}}
+
+	var s string
+	n := 0
+	for _, u := range units {
+		if v := d / u.d; v > 0 || n > 0 {
+			if v > 0 {
+				s += fmt.Sprintf("%d%s", v, u.name)
+			}
+			d -= v * u.d
+			if n++; n == 2 {
+				break
+			}
+		}
+	}
+	return s
+}
+
+// Age provides the age of a timestamp relative to the current time
+// (like 5m ago). The raw value is the age in seconds.
+func Age(t time.Time) output.Value {
+	return AgeAt(t, time.Now())
+}
+
+// AgeAt provides the age of a timestamp relative to the given time.
+func AgeAt(t, now time.Time) output.Value {
+	if t.IsZero() {
+		return output.Plain("")
+	}
+	d := now.Sub(t)
+	return output.Value{Raw: strconv.FormatInt(int64(d/time.Second), 10), Human: FormatAge(d)}
+}
+
+// FormatAge formats an age by its most significant unit.
+// Negative ages describe future timestamps (like in 5m).
+func FormatAge(d time.Duration) string {
+	if d < 0 {
+		return "in " + age(-d)
+	}
+	return age(d) + " ago"
+}
+
+func age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", d/time.Second)
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dd", d/(24*time.Hour))
+}
+
+// Timestamp provides a timestamp in RFC3339 format.
+// The raw value is the Unix time in seconds.
+func Timestamp(t time.Time) output.Value {
+	if t.IsZero() {
+		return output.Plain("")
+	}
+	return output.Value{Raw: strconv.FormatInt(t.Unix(), 10), Human: t.Format(time.RFC3339)}
+}
+
+// Bool provides a boolean shown as yes or no.
+// The raw value is true or false.
+func Bool(b bool) output.Value {
+	if b {
+		return output.Value{Raw: "true", Human: "yes"}
+	}
+	return output.Value{Raw: "false", Human: "no"}
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
@@ -0,0 +1,46 @@
+package humanize_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils/humanize"
+	"github.com/mandelsoft/flagutils/output"
+)
+
+var _ = Describe("formatting", func() {
+	It("formats byte sizes", func() {
+		Expect(humanize.BytesSI(999)).To(Equal(output.Value{Raw: "999", Human: "999 B"}))
+		Expect(humanize.BytesSI(1500)).To(Equal(output.Value{Raw: "1500", Human: "1.5 kB"}))
+		Expect(humanize.BytesSI(2000000).Human).To(Equal("2 MB"))
+		Expect(humanize.BytesIEC(1536)).To(Equal(output.Value{Raw: "1536", Human: "1.5 KiB"}))
+		Expect(humanize.BytesIEC(3 << 30).Human).To(Equal("3 GiB"))
+	})
+
+	It("formats durations", func() {
+		Expect(humanize.Duration(65 * time.Minute)).To(Equal(output.Value{Raw: "3900", Human: "1h5m"}))
+		Expect(humanize.FormatDuration(51 * time.Hour)).To(Equal("2d3h"))
+		Expect(humanize.FormatDuration(42 * time.Second)).To(Equal("42s"))
+		Expect(humanize.FormatDuration(350 * time.Millisecond)).To(Equal("350ms"))
+	})
+
+	It("formats ages", func() {
+		now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+		Expect(humanize.AgeAt(now.Add(-5*time.Minute), now)).To(Equal(output.Value{Raw: "300", Human: "5m ago"}))
+		Expect(humanize.AgeAt(now.Add(-50*time.Hour), now).Human).To(Equal("2d ago"))
+		Expect(humanize.AgeAt(now.Add(10*time.Second), now).Human).To(Equal("in 10s"))
+		Expect(humanize.AgeAt(time.Time{}, now)).To(Equal(output.Plain("")))
+	})
+
+	It("formats timestamps", func() {
+		t := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+		Expect(humanize.Timestamp(t)).To(Equal(output.Value{Raw: "1767355200", Human: "2026-01-02T12:00:00Z"}))
+	})
+
+	It("formats booleans", func() {
+		Expect(humanize.Bool(true)).To(Equal(output.Value{Raw: "true", Human: "yes"}))
+		Expect(humanize.Bool(false)).To(Equal(output.Value{Raw: "false", Human: "no"}))
+	})
+})
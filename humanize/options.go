@@ -0,0 +1,87 @@
+package humanize
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/goutils/general"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+// Options is the option pair --human-readable / --raw selecting
+// the representation of Values (see output.Value) shown by
+// the table outputs.
+type Options struct {
+	human flagutils.SimpleOption[bool, *Options]
+	raw   flagutils.SimpleOption[bool, *Options]
+	def   bool
+}
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+var (
+	_ flagutils.Options     = (*Options)(nil)
+	_ flagutils.Validatable = (*Options)(nil)
+)
+
+// New creates the option. Optionally, the default representation
+// can be set (default is human-readable).
+func New(human ...bool) *Options {
+	o := &Options{def: general.OptionalDefaultedBool(true, human...)}
+	o.human = flagutils.NewSimpleOption[bool](o, false, "human-readable", "", "show human-readable values")
+	o.raw = flagutils.NewSimpleOption[bool](o, false, "raw", "", "show raw values")
+	return o
+}
+
+func (o *Options) WithHumanReadableNames(long, short string) *Options {
+	return o.human.WithNames(long, short)
+}
+
+func (o *Options) WithHumanReadableDescription(s string) *Options {
+	return o.human.WithDescription(s)
+}
+
+func (o *Options) WithRawNames(long, short string) *Options {
+	return o.raw.WithNames(long, short)
+}
+
+func (o *Options) WithRawDescription(s string) *Options {
+	return o.raw.WithDescription(s)
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.human.AddFlags(fs)
+	o.raw.AddFlags(fs)
+}
+
+func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flagutils.ValidationSet) error {
+	if o.human.Value() && o.raw.Value() {
+		h, _ := o.human.GetNames()
+		r, _ := o.raw.GetNames()
+		return fmt.Errorf("--%s and --%s are exclusive", h, r)
+	}
+	return nil
+}
+
+// IsRaw reports whether raw values are requested.
+func (o *Options) IsRaw() bool {
+	if o.raw.Value() {
+		return true
+	}
+	if o.human.Value() {
+		return false
+	}
+	return !o.def
+}
+
+// IsRaw reports whether raw values are requested by an option set.
+// Without the option, human-readable values are used.
+func IsRaw(opts flagutils.OptionSetProvider) bool {
+	o := From(opts)
+	return o != nil && o.IsRaw()
+}
@@ -0,0 +1,102 @@
+package humanize_test
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/humanize"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/sort"
+	"github.com/mandelsoft/flagutils/summary"
+)
+
+type file struct {
+	name string
+	size int64
+}
+
+type source []*file
+
+func (s source) Elements(output.ElementSpecs) (iter.Seq[*file], error) {
+	return slices.Values(s), nil
+}
+
+var _ = Describe("human-readable option", func() {
+	var set flagutils.OptionSet
+
+	elements := source{
+		{"a", 2048},
+		{"b", 999},
+		{"c", 10240},
+	}
+
+	mapper := func(e *file) *output.Values {
+		return &output.Values{output.Plain(e.name), humanize.BytesIEC(e.size)}
+	}
+
+	setupWithSort := func(s *sort.Options, opts ...flagutils.Options) {
+		f := tableoutput.NewOutputFactory[*file](mapper, "NAME", "-SIZE").WithSummary(output.Total("SIZE"))
+		set = flagutils.NewOptionSet(append(opts, s, summary.New(), tableoutput.New(), output.New(output.NewOutputsFactory[*file]().Add("", f)))...)
+	}
+
+	setup := func(opts ...flagutils.Options) {
+		setupWithSort(sort.New().WithNumericFields("size"), opts...)
+	}
+
+	run := func(args ...string) *flagutilstest.Result {
+		return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			_, err := output.From[*file](opts).GetOutput().Process(ctx, nil, elements)
+			return err
+		}), args...)
+	}
+
+	It("shows human-readable values sorted by raw values", func() {
+		setup(humanize.New())
+		r := run("-s", "size", "--summary")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`NAME        SIZE
+b          999 B
+a          2 KiB
+c         10 KiB
+TOTAL (3)  13287
+`))
+	})
+
+	It("sorts raw values lexically by default", func() {
+		setupWithSort(sort.New(), humanize.New())
+		r := run("-s", "size", "--raw")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME  SIZE\nc    10240\na     2048\nb      999\n"))
+	})
+
+	It("shows raw values", func() {
+		setup(humanize.New())
+		r := run("-s", "size", "--raw")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME  SIZE\nb      999\na     2048\nc    10240\n"))
+	})
+
+	It("uses the configured default", func() {
+		setup(humanize.New(false))
+		r := run()
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME  SIZE\na     2048\nb      999\nc    10240\n"))
+
+		r = run("--human-readable")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(ContainSubstring("2 KiB"))
+	})
+
+	It("rejects both flags", func() {
+		setup(humanize.New())
+		r := run("--raw", "--human-readable")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring("--human-readable and --raw are exclusive")))
+	})
+})
@@ -60,8 +60,8 @@ func (s *DefaultOptionSet) AddFlags(fs *pflag.FlagSet) {
 	if s == nil {
 		return
 	}
-	for _, o := range *s {
-		o.AddFlags(fs)
+	if err := Check(s, fs); err != nil {
+		panic(err)
 	}
 }
 
@@ -0,0 +1,274 @@
+package flagutils
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// FlagRequiredAnnotation is the pflag.Flag annotation used to
+	// mark flags, which must be given.
+	FlagRequiredAnnotation = "flagutils-required"
+	// FlagSecretAnnotation is the pflag.Flag annotation used to
+	// mark flags with secret values.
+	FlagSecretAnnotation = "flagutils-secret"
+	// FlagChoicesAnnotation is the pflag.Flag annotation used to
+	// record the possible values of a flag.
+	FlagChoicesAnnotation = "flagutils-choices"
+	// FlagOpenChoicesAnnotation is the pflag.Flag annotation used to
+	// mark the recorded choices of a flag as proposals. Other values
+	// are accepted, also.
+	FlagOpenChoicesAnnotation = "flagutils-open-choices"
+)
+
+// MAX_PROMPT_ROUNDS is the maximum number of prompting rounds
+// executed by ValidateInteractively before the validation error is
+// finally reported.
+const MAX_PROMPT_ROUNDS = 3
+
+// IsRequiredFlag reports whether a flag is marked as required.
+func IsRequiredFlag(f *pflag.Flag) bool {
+	return f.Annotations != nil && len(f.Annotations[FlagRequiredAnnotation]) > 0
+}
+
+// SecretFlagValue is implemented by pflag.Value types for secrets.
+type SecretFlagValue interface {
+	IsSecret() bool
+}
+
+// IsSecretFlag reports whether a flag is marked as secret
+// or has a SecretFlagValue.
+func IsSecretFlag(f *pflag.Flag) bool {
+	if s, ok := f.Value.(SecretFlagValue); ok && s.IsSecret() {
+		return true
+	}
+	return f.Annotations != nil && len(f.Annotations[FlagSecretAnnotation]) > 0
+}
+
+// SetFlagChoices records the possible values of a flag.
+func SetFlagChoices(f *pflag.Flag, choices ...string) {
+	annotateFlag(f, FlagChoicesAnnotation, choices...)
+}
+
+// GetFlagChoices provides the possible values of a flag, if known.
+func GetFlagChoices(f *pflag.Flag) []string {
+	if f.Annotations == nil {
+		return nil
+	}
+	return f.Annotations[FlagChoicesAnnotation]
+}
+
+// SetFlagOpenChoices records the proposed values of a flag.
+// In contrast to SetFlagChoices, other values are accepted, also.
+func SetFlagOpenChoices(f *pflag.Flag, choices ...string) {
+	SetFlagChoices(f, choices...)
+	annotateFlag(f, FlagOpenChoicesAnnotation, "true")
+}
+
+// HasOpenFlagChoices reports whether the choices of a flag
+// are just proposals (see SetFlagOpenChoices).
+func HasOpenFlagChoices(f *pflag.Flag) bool {
+	return f.Annotations != nil && len(f.Annotations[FlagOpenChoicesAnnotation]) > 0
+}
+
+// MissingFlagsError is provided by CheckRequired for
+// required flags, which are not given.
+type MissingFlagsError struct {
+	Names []string
+}
+
+func (e *MissingFlagsError) Error() string {
+	return fmt.Sprintf("required flags not set: --%s", strings.Join(e.Names, ", --"))
+}
+
+// CheckRequired checks whether all required flags of a flag set are given.
+func CheckRequired(fs *pflag.FlagSet) error {
+	var missing []string
+	fs.VisitAll(func(f *pflag.Flag) {
+		if IsRequiredFlag(f) && !f.Changed {
+			missing = append(missing, f.Name)
+		}
+	})
+	if len(missing) > 0 {
+		return &MissingFlagsError{missing}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// PromptRequest describes a flag value requested from the user.
+type PromptRequest struct {
+	Flag *pflag.Flag
+	// Choices is the list of possible values, if known.
+	Choices []string
+	// Open indicates that values other than the Choices are accepted.
+	Open bool
+	// Secret requests masked input.
+	Secret bool
+	// Cause is the problem, which should be solved by the requested value.
+	Cause error
+}
+
+// Prompter is used by ValidateInteractively to request flag values.
+// An empty answer keeps the actual value. If no more input is
+// available io.EOF should be returned.
+// Package prompt provides an implementation for terminals.
+type Prompter interface {
+	Prompt(ctx context.Context, req *PromptRequest) (string, error)
+}
+
+type prompterKey struct{}
+
+// WithPrompter provides a context enabling the interactive mode
+// of the lifecycle executors with the given Prompter.
+func WithPrompter(ctx context.Context, p Prompter) context.Context {
+	return context.WithValue(ctx, prompterKey{}, p)
+}
+
+// GetPrompter provides the Prompter configured for a context.
+// Without a Prompter, nil is returned.
+func GetPrompter(ctx context.Context) Prompter {
+	p, _ := ctx.Value(prompterKey{}).(Prompter)
+	return p
+}
+
+// validationFailure records the innermost Options object
+// failing the validation.
+type validationFailure struct {
+	options any
+}
+
+type validationFailureKey struct{}
+
+func recordValidationFailure(ctx context.Context, o any) {
+	if f, ok := ctx.Value(validationFailureKey{}).(*validationFailure); ok && f.options == nil {
+		f.options = o
+	}
+}
+
+// ValidateInteractively checks the required flags and validates
+// an OptionSet, which must contain a FlagSetRecorder.
+// If the context provides a Prompter (see WithPrompter), the values of
+// missing flags and of the flags of the Options object failing the
+// validation are requested from the user and the validation is repeated.
+// Without Prompter it just reports the problem.
+// It is used by the lifecycle executors instead of Validate.
+func ValidateInteractively(ctx context.Context, set OptionSetProvider) error {
+	opts := set.AsOptionSet()
+	fs := GetFlagSet(opts)
+	p := GetPrompter(ctx)
+
+	for round := 0; ; round++ {
+		var err error
+		failure := &validationFailure{}
+		if fs != nil {
+			err = CheckRequired(fs)
+		}
+		if err == nil {
+			vctx := ctx
+			if p != nil {
+				vctx = context.WithValue(ctx, validationFailureKey{}, failure)
+			}
+			err = Validate(vctx, opts, nil)
+		}
+		if err == nil || p == nil || fs == nil || round == MAX_PROMPT_ROUNDS {
+			return err
+		}
+
+		flags := promptFlags(fs, err, failure.options)
+		if len(flags) == 0 {
+			return err
+		}
+		for _, f := range flags {
+			req := &PromptRequest{
+				Flag:    f,
+				Choices: GetFlagChoices(f),
+				Open:    HasOpenFlagChoices(f),
+				Secret:  IsSecretFlag(f),
+				Cause:   err,
+			}
+			for {
+				v, perr := p.Prompt(ctx, req)
+				if perr != nil {
+					if errors.Is(perr, io.EOF) {
+						return err
+					}
+					return perr
+				}
+				if v == "" {
+					break
+				}
+				perr = setPrompted(fs, f, v)
+				if perr == nil {
+					break
+				}
+				req.Cause = errors.Wrapf(perr, "invalid value for --%s", f.Name)
+			}
+		}
+	}
+}
+
+// promptFlags determines the flags to request for a validation problem.
+// These are the missing required flags or the (changed) flags of the
+// Options object failing the validation.
+func promptFlags(fs *pflag.FlagSet, err error, failed any) []*pflag.Flag {
+	var missing *MissingFlagsError
+	if errors.As(err, &missing) {
+		var flags []*pflag.Flag
+		for _, n := range missing.Names {
+			flags = append(flags, fs.Lookup(n))
+		}
+		return flags
+	}
+
+	o, ok := failed.(Options)
+	if !ok {
+		return nil
+	}
+	var all, changed []*pflag.Flag
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || FlagAliasTarget(f) != nil || !IsFlagOf(f, o) {
+			return
+		}
+		all = append(all, f)
+		if f.Changed {
+			changed = append(changed, f)
+		}
+	})
+	if len(changed) > 0 {
+		return changed
+	}
+	return all
+}
+
+// setPrompted sets a flag to an interactively provided value.
+// The values of slice flags (like stringSlice) are replaced by the comma
+// separated values. Other flags implementing pflag.SliceValue, like
+// stringArray, get the value as single element, because their
+// elements may contain commas.
+func setPrompted(fs *pflag.FlagSet, f *pflag.Flag, v string) error {
+	if s, ok := f.Value.(pflag.SliceValue); ok {
+		list := []string{v}
+		if strings.HasSuffix(f.Value.Type(), "Slice") {
+			var err error
+			list, err = csv.NewReader(strings.NewReader(v)).Read()
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.Replace(list); err != nil {
+			return err
+		}
+		f.Changed = true
+		SetOrigin(f, NewOrigin(ORIGIN_INTERACTIVE))
+		return nil
+	}
+	return SetFlag(fs, f.Name, v, NewOrigin(ORIGIN_INTERACTIVE))
+}
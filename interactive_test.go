@@ -0,0 +1,113 @@
+package flagutils_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/prompt"
+	"github.com/spf13/pflag"
+)
+
+type TargetOption struct {
+	flagutils.SimpleOption[string, *TargetOption]
+}
+
+func NewTargetOption() *TargetOption {
+	o := &TargetOption{}
+	o.SimpleOption = flagutils.NewSimpleOption[string](o, "", "target", "", "deployment target")
+	return o.WithRequired().WithChoices("dev", "prod")
+}
+
+type ListOptions struct {
+	modes  flagutils.SimpleOption[[]string, *ListOptions]
+	labels flagutils.SimpleOption[[]string, *ListOptions]
+}
+
+func NewListOptions() *ListOptions {
+	o := &ListOptions{}
+	o.modes = flagutils.NewSimpleOptionWithSetter[[]string](o, (*pflag.FlagSet).StringArrayVarP, nil, "mode", "", "output modes")
+	o.modes.WithRequired()
+	o.labels = flagutils.NewSimpleOption[[]string](o, nil, "labels", "", "labels")
+	o.labels.WithRequired()
+	return o
+}
+
+func (o *ListOptions) AddFlags(fs *pflag.FlagSet) {
+	o.modes.AddFlags(fs)
+	o.labels.AddFlags(fs)
+}
+
+var _ = Describe("interactive validation", func() {
+	var target *TargetOption
+	var popt *parallel.Options
+	var set flagutils.ExtendableOptionSet
+	var out *bytes.Buffer
+
+	nop := RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error { return nil })
+
+	interactive := func(input string) context.Context {
+		return flagutils.WithPrompter(context.Background(), prompt.New(strings.NewReader(input), out))
+	}
+
+	BeforeEach(func() {
+		target = NewTargetOption()
+		popt = parallel.New()
+		set = flagutils.NewOptionSet(target, popt)
+		out = &bytes.Buffer{}
+	})
+
+	It("reports missing required flags", func() {
+		Expect(flagutils.ExecuteLifecycle(context.Background(), "test", set, nop)).To(MatchError("validation failed: required flags not set: --target"))
+	})
+
+	It("requests missing required flags", func() {
+		var origin flagutils.Origin
+		MustBeSuccessful(flagutils.ExecuteLifecycle(interactive("2\n"), "test", set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			origin = flagutils.Provenance(opts, "target")
+			return nil
+		})))
+		Expect(target.Value()).To(Equal("prod"))
+		Expect(origin).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_INTERACTIVE)))
+		Expect(out.String()).To(Equal(`required flags not set: --target
+--target: deployment target
+  1) dev
+  2) prod
+target: `))
+	})
+
+	It("repeats invalid choices", func() {
+		MustBeSuccessful(flagutils.ExecuteLifecycle(interactive("test\ndev\n"), "test", set, nop))
+		Expect(target.Value()).To(Equal("dev"))
+		Expect(out.String()).To(ContainSubstring("invalid choice \"test\"\ntarget: "))
+	})
+
+	It("requests values for invalid options", func() {
+		MustBeSuccessful(flagutils.ExecuteLifecycle(interactive("3\n"), "test", set, nop, "--target", "dev", "-p", "-1"))
+		Expect(popt.Value()).To(Equal(3))
+		Expect(out.String()).To(HavePrefix(`invalid degree of parallelism: -1 (--parallel from command line)
+--parallel: degree of parallelism
+parallel [-1]: `))
+	})
+
+	It("reports the validation error without further input", func() {
+		Expect(flagutils.ExecuteLifecycle(interactive(""), "test", set, nop, "--target", "dev", "-p", "-1")).To(MatchError("validation failed: invalid degree of parallelism: -1 (--parallel from command line)"))
+	})
+
+	It("keeps commas of array elements", func() {
+		l := NewListOptions()
+		MustBeSuccessful(flagutils.ExecuteLifecycle(interactive("a,b\ncustom-columns=A:.a,B:.b\n"), "test", flagutils.NewOptionSet(l), nop))
+		Expect(l.modes.Value()).To(Equal([]string{"custom-columns=A:.a,B:.b"}))
+		Expect(l.labels.Value()).To(Equal([]string{"a", "b"}))
+	})
+
+	It("gives up after max rounds", func() {
+		Expect(flagutils.ExecuteLifecycle(interactive("-1\n-2\n-3\n-4\n"), "test", set, nop, "--target", "dev", "-p", "-1")).To(MatchError("validation failed: invalid degree of parallelism: -3 (--parallel from interactive input)"))
+	})
+})
@@ -2,9 +2,14 @@ package flagutils
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"slices"
 
-	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/set"
 	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils/utils/out"
 )
 
 // Prepare checks whether the provided OptionSetProvider or its nested options
@@ -14,12 +19,21 @@ import (
 // it is added to a pflag.FlagSet. It is intended to link various options,
 // check whether thay are compatible and prepare dependent default values
 // and/or values helps.
+// Finally, the flag aliases declared by FlagAliasProvider objects
+// are checked for collisions.
+// Registered LifecycleObservers are notified about the phase and
+// the prepared objects. This applies to Validate and Finalize, also.
 func Prepare(ctx context.Context, set OptionSetProvider, val PreparationSet) error {
 	if val == nil {
 		val = PreparationSet{}
 	}
 	base := set.AsOptionSet()
-	return val.Prepare(ctx, base, base)
+	return observePhase(ctx, PHASE_PREPARE, base, func(ctx context.Context) error {
+		if err := val.Prepare(ctx, base, base); err != nil {
+			return err
+		}
+		return checkFlagAliases(base)
+	})
 }
 
 // Validate checks whether the provided OptionSetProvider or its nested options
@@ -30,15 +44,12 @@ func Validate(ctx context.Context, set OptionSetProvider, val ValidationSet) err
 		val = ValidationSet{}
 	}
 	base := set.AsOptionSet()
-	if v, ok := set.(Validatable); ok {
-		err := v.Validate(ctx, base, val)
-		if err != nil {
-			return err
+	return observePhase(ctx, PHASE_VALIDATE, base, func(ctx context.Context) error {
+		if v, ok := set.(Validatable); ok {
+			return v.Validate(ctx, base, val)
 		}
-	} else {
 		return val.ValidateSet(ctx, base, base)
-	}
-	return nil
+	})
 }
 
 // Finalize checks whether the provided OptionSetProvider or its nested options
@@ -48,7 +59,10 @@ func Finalize(ctx context.Context, set OptionSetProvider, val FinalizationSet) e
 	if val == nil {
 		val = FinalizationSet{}
 	}
-	return val.FinalizeSet(ctx, set.AsOptionSet(), set)
+	base := set.AsOptionSet()
+	return observePhase(ctx, PHASE_FINALIZE, base, func(ctx context.Context) error {
+		return val.FinalizeSet(ctx, base, set)
+	})
 }
 
 // Runner is the interface used to run an aplication based on an option lifecycle management.
@@ -58,23 +72,102 @@ type Runner interface {
 
 // ExecuteLifecycle is a default lifecycle executor based on a Runner used to
 // run the application in the run phase.
+// Before the options are added to the flag set, they are checked for
+// flag collisions (see Check), which are resolved according to the
+// CollisionPolicy of the option set.
+// If the context provides a Prompter, missing or invalid flag values
+// are requested interactively (see ValidateInteractively).
+// The Runner is called with the context provided by RunContext and
+// a failure of the run is passed to the finalization (see WithFailure).
+// The lifecycle is executed by a LifecycleRunner with its default settings,
+// which handles signals, recovers panics and guarantees the finalization.
+// Failures are reported by a LifecycleError.
 func ExecuteLifecycle(ctx context.Context, name string, options OptionSetProvider, run Runner, args ...string) error {
+	return NewLifecycleRunner(name).Execute(ctx, options, run, args...)
+}
+
+// lifecycle is the option set and the pflag.FlagSet used
+// by a lifecycle execution.
+// Option sets are added incrementally, for example, for the commands on
+// the path to an executed sub command. The given option sets are kept
+// unchanged, they are combined in a separate OptionSet.
+// The usage of the flag set additionally describes the effective
+// flag values with their origin (see ProvenanceUsages).
+type lifecycle struct {
+	opts     ExtendableOptionSet
+	fs       *pflag.FlagSet
+	prepared PreparationSet
+	added    []Options
+	// inherited are the flags added before the last option set.
+	inherited set.Set[string]
+}
+
+func newLifecycle(ctx context.Context, name string) *lifecycle {
 	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
-	if ctx == nil {
-		ctx = context.Background()
+	fs.SetOutput(out.Get(ctx).Stderr())
+	fs.Usage = func() { usage(fs.Name(), fs) }
+	return &lifecycle{
+		opts:      NewOptionSet(),
+		fs:        fs,
+		prepared:  PreparationSet{},
+		inherited: set.New[string](),
 	}
+}
 
-	opts := options.AsOptionSet()
-	if err := Prepare(ctx, opts, nil); err != nil {
+// add adds an option set. The combined set is prepared again, but
+// Options objects already prepared are skipped. Afterward, the flags of
+// all Options objects not yet known are added to the flag set (see Check).
+func (l *lifecycle) add(ctx context.Context, opts OptionSetProvider) error {
+	l.fs.VisitAll(func(f *pflag.Flag) {
+		l.inherited.Add(f.Name)
+	})
+	if opts == nil {
+		return nil
+	}
+	l.opts.Add(opts.AsOptionSet())
+	if err := Prepare(ctx, l.opts, l.prepared); err != nil {
 		return err
 	}
-	opts.AddFlags(fs)
+	var list []Options
+	for _, o := range leafOptions(l.opts, nil) {
+		if !slices.ContainsFunc(l.added, func(e Options) bool { return sameOptions(e, o) }) {
+			list = append(list, o)
+		}
+	}
+	l.added = append(l.added, list...)
+	return addChecked(l.opts, list, l.fs)
+}
 
-	if err := fs.Parse(args); err != nil {
-		return err
+// complete adds a local FlagSetRecorder to the combined set,
+// if the given option sets do not contain one.
+func (l *lifecycle) complete() {
+	if GetFrom[*FlagSetRecorder](l.opts) == nil {
+		r := NewFlagSetRecorder()
+		r.BindFlagSet(l.fs)
+		l.opts.Add(r)
 	}
-	if err := Validate(ctx, opts, nil); err != nil {
-		return err
+}
+
+// sameOptions checks whether two Options objects are identical.
+// Objects of non-comparable types are never identical.
+func sameOptions(a, b Options) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// parse parses the arguments for the flag set of an OptionSet
+// as lifecycle phase notifying the LifecycleObservers.
+func parse(ctx context.Context, opts OptionSet, fs *pflag.FlagSet, args []string) error {
+	return observePhase(ctx, PHASE_PARSE, opts, func(ctx context.Context) error {
+		return Parse(fs, args)
+	})
+}
+
+// usage writes the flag usages of a flag set followed by the
+// non-default flag values already set.
+func usage(name string, fs *pflag.FlagSet) {
+	fmt.Fprintf(fs.Output(), "Usage of %s:\n%s", name, fs.FlagUsages())
+	if p := ProvenanceUsages(fs); p != "" {
+		fmt.Fprintf(fs.Output(), "\nEffective values:\n%s", p)
 	}
-	return errors.Join(run.Run(ctx, opts), Finalize(ctx, opts, nil))
 }
@@ -29,7 +29,7 @@ func (s FinalizationSet) Finalize(ctx context.Context, opts OptionSet, orig any)
 		if v, ok := o.(Finalizable); ok {
 			if !set.Set[Finalizable](s).Has(v) {
 				set.Set[Finalizable](s).Add(v)
-				return v.Finalize(ctx, opts, s)
+				return observe(ctx, PHASE_FINALIZE, orig, func(ctx context.Context) error { return v.Finalize(ctx, opts, s) })
 			}
 			return nil
 		}
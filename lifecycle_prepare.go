@@ -31,7 +31,7 @@ func (s PreparationSet) Prepare(ctx context.Context, opts OptionSet, orig any) e
 		if v, ok := o.(Preparable); ok {
 			if !set.Set[Preparable](s).Has(v) {
 				set.Set[Preparable](s).Add(v)
-				return v.Prepare(ctx, opts, s)
+				return observe(ctx, PHASE_PREPARE, orig, func(ctx context.Context) error { return v.Prepare(ctx, opts, s) })
 			}
 			return nil
 		}
@@ -0,0 +1,325 @@
+package flagutils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// Phase describes a phase of the option lifecycle.
+type Phase string
+
+const (
+	PHASE_PREPARE  Phase = "preparation"
+	PHASE_PARSE    Phase = "parsing"
+	PHASE_VALIDATE Phase = "validation"
+	PHASE_RUN      Phase = "run"
+	PHASE_FINALIZE Phase = "finalization"
+)
+
+// Exit codes provided by LifecycleError.ExitCode.
+// Terminations by a signal use 128 plus the signal number.
+const (
+	EXIT_SUCCESS  = 0
+	EXIT_FAILURE  = 1
+	EXIT_USAGE    = 2
+	EXIT_FINALIZE = 3
+	EXIT_PANIC    = 70
+)
+
+// ExitCoder is implemented by errors providing a dedicated
+// exit code for a failed run phase.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// DEFAULT_FINALIZE_TIMEOUT is the default timeout for the finalization
+// executed by a LifecycleRunner.
+const DEFAULT_FINALIZE_TIMEOUT = 30 * time.Second
+
+// LifecycleError is the error provided by a LifecycleRunner.
+// It describes the failed phase and, additionally, a failed finalization.
+type LifecycleError struct {
+	Phase Phase
+	Err   error
+	// Finalize is the finalization error, if the finalization failed
+	// after a failure of another phase.
+	Finalize error
+	// Signal is the signal terminating the run.
+	Signal os.Signal
+	// Panic is the recovered panic value.
+	Panic any
+	// Stack is the stack trace of the recovered panic.
+	Stack string
+	// Abandoned reports that the canceled run phase did not return
+	// within the finalize timeout. Because it might still use the
+	// options, the finalization is skipped.
+	Abandoned bool
+}
+
+func (e *LifecycleError) Error() string {
+	msg := fmt.Sprintf("%s failed: %s", e.Phase, e.Err)
+	if e.Finalize != nil {
+		msg += fmt.Sprintf("; %s failed: %s", PHASE_FINALIZE, e.Finalize)
+	}
+	if e.Abandoned {
+		msg += fmt.Sprintf("; %s abandoned, %s skipped", e.Phase, PHASE_FINALIZE)
+	}
+	return msg
+}
+
+func (e *LifecycleError) Unwrap() []error {
+	list := []error{e.Err}
+	if e.Finalize != nil {
+		list = append(list, e.Finalize)
+	}
+	return list
+}
+
+// ExitCode provides the exit code for the failure.
+func (e *LifecycleError) ExitCode() int {
+	if e.Signal != nil {
+		if s, ok := e.Signal.(syscall.Signal); ok {
+			return 128 + int(s)
+		}
+		return EXIT_FAILURE
+	}
+	if e.Panic != nil {
+		return EXIT_PANIC
+	}
+	switch e.Phase {
+	case PHASE_PREPARE, PHASE_PARSE, PHASE_VALIDATE:
+		return EXIT_USAGE
+	case PHASE_FINALIZE:
+		return EXIT_FINALIZE
+	}
+	var ec ExitCoder
+	if errors.As(e.Err, &ec) {
+		return ec.ExitCode()
+	}
+	return EXIT_FAILURE
+}
+
+// ExitCode provides the exit code for an error returned by a lifecycle
+// execution. For other errors the code of an ExitCoder is used, or
+// EXIT_FAILURE is returned.
+func ExitCode(err error) int {
+	if err == nil {
+		return EXIT_SUCCESS
+	}
+	var lerr *LifecycleError
+	if errors.As(err, &lerr) {
+		return lerr.ExitCode()
+	}
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return EXIT_FAILURE
+}
+
+// SignalError is the cause of the context cancellation
+// by a signal handled by a LifecycleRunner.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("interrupted by signal %s", e.Signal)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// LifecycleRunner executes the lifecycle of an OptionSet (see ExecuteLifecycle)
+// and guarantees the finalization.
+//   - Signals (by default SIGINT and SIGTERM) cancel the context passed to the
+//     lifecycle phases. A canceled run phase is awaited for the finalize
+//     timeout. If it does not return, it is abandoned (see LifecycleError.Abandoned).
+//     An interactive validation waiting for input is not awaited.
+//   - Panics are recovered and reported as error.
+//   - Once the validation has been started, Finalize is always executed
+//     with a timeout, even if the context has been canceled. Only an
+//     abandoned run phase skips the finalization. On a timeout, the context
+//     passed to the finalizers is canceled. A finalizer ignoring it
+//     cannot be stopped and keeps running in the background until it returns.
+//   - The run phase uses the context provided by RunContext and the
+//     finalization gets access to a failure (see GetFailure).
+//
+// Failures are reported by a LifecycleError providing an exit code.
+type LifecycleRunner struct {
+	name    string
+	signals []os.Signal
+	timeout time.Duration
+}
+
+func NewLifecycleRunner(name string) *LifecycleRunner {
+	return &LifecycleRunner{
+		name:    name,
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+		timeout: DEFAULT_FINALIZE_TIMEOUT,
+	}
+}
+
+// WithSignals sets the handled signals. Without signals
+// no signal handling is installed.
+func (r *LifecycleRunner) WithSignals(sigs ...os.Signal) *LifecycleRunner {
+	r.signals = sigs
+	return r
+}
+
+// WithFinalizeTimeout sets the timeout for the finalization and the
+// grace period for a canceled run phase.
+func (r *LifecycleRunner) WithFinalizeTimeout(d time.Duration) *LifecycleRunner {
+	r.timeout = d
+	return r
+}
+
+// Execute executes the lifecycle for the given options and arguments.
+func (r *LifecycleRunner) Execute(ctx context.Context, options OptionSetProvider, run Runner, args ...string) error {
+	return r.execute(ctx, func(ctx context.Context) (*lifecycle, error) {
+		l := newLifecycle(ctx, r.name)
+		return l, l.add(ctx, options)
+	}, run, args...)
+}
+
+// execute executes the lifecycle for the option sets
+// added by the setup function in the preparation phase.
+func (r *LifecycleRunner) execute(ctx context.Context, setup func(ctx context.Context) (*lifecycle, error), run Runner, args ...string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if len(r.signals) > 0 {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, r.signals...)
+		defer signal.Stop(sigs)
+		go func() {
+			select {
+			case s := <-sigs:
+				cancel(&SignalError{s})
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	var l *lifecycle
+	if err := r.call(PHASE_PREPARE, func() (err error) {
+		l, err = setup(ctx)
+		return err
+	}); err != nil {
+		return err
+	}
+	l.complete()
+	opts, fs := l.opts, l.fs
+	if err := r.call(PHASE_PARSE, func() error { return parse(ctx, opts, fs, args) }); err != nil {
+		return err
+	}
+
+	validate := func() error { return ValidateInteractively(ctx, opts) }
+	var err error
+	if GetPrompter(ctx) != nil {
+		// waiting for user input must not block the signal handling.
+		err = r.async(ctx, PHASE_VALIDATE, 0, validate)
+	} else {
+		err = r.call(PHASE_VALIDATE, validate)
+	}
+	if err == nil {
+		err = r.async(ctx, PHASE_RUN, r.timeout, func() error { return runWithContext(ctx, opts, run) })
+	}
+	var lerr *LifecycleError
+	if errors.As(err, &lerr) && lerr.Abandoned {
+		return err
+	}
+	return r.finalize(ctx, opts, err)
+}
+
+// async executes a phase function in a separate go routine.
+// If the context is canceled, it is awaited for the grace period
+// and abandoned afterward.
+func (r *LifecycleRunner) async(ctx context.Context, phase Phase, grace time.Duration, f func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- r.call(phase, f)
+	}()
+	var err error
+	abandoned := false
+	select {
+	case err = <-done:
+		if err == nil {
+			return nil
+		}
+	case <-ctx.Done():
+		if grace > 0 {
+			timer := time.NewTimer(grace)
+			defer timer.Stop()
+			select {
+			case err = <-done:
+			case <-timer.C:
+				abandoned = true
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		var serr *SignalError
+		if errors.As(context.Cause(ctx), &serr) {
+			return &LifecycleError{Phase: phase, Err: serr, Signal: serr.Signal, Abandoned: abandoned}
+		}
+		if err == nil {
+			return &LifecycleError{Phase: phase, Err: context.Cause(ctx), Abandoned: abandoned}
+		}
+	}
+	return err
+}
+
+func (r *LifecycleRunner) finalize(ctx context.Context, opts OptionSet, err error) error {
+	fctx, cancel := context.WithTimeout(WithFailure(context.WithoutCancel(ctx), err), r.timeout)
+	defer cancel()
+
+	// buffered, so that the go routine terminates after a timeout
+	// as soon as the finalizers return.
+	done := make(chan error, 1)
+	go func() {
+		done <- r.call(PHASE_FINALIZE, func() error { return Finalize(fctx, opts, nil) })
+	}()
+
+	var ferr error // always a *LifecycleError
+	select {
+	case ferr = <-done:
+	case <-fctx.Done():
+		ferr = &LifecycleError{Phase: PHASE_FINALIZE, Err: fmt.Errorf("timeout after %s", r.timeout)}
+	}
+
+	if err == nil {
+		return ferr
+	}
+	if ferr != nil {
+		var lerr *LifecycleError
+		if errors.As(err, &lerr) {
+			lerr.Finalize = ferr.(*LifecycleError).Err
+		}
+	}
+	return err
+}
+
+// call executes a phase function, recovers panics and
+// wraps errors into a LifecycleError.
+func (r *LifecycleRunner) call(phase Phase, f func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &LifecycleError{Phase: phase, Err: fmt.Errorf("panic: %v", p), Panic: p, Stack: strings.TrimSpace(string(debug.Stack()))}
+		}
+	}()
+	if err := f(); err != nil {
+		return &LifecycleError{Phase: phase, Err: err}
+	}
+	return nil
+}
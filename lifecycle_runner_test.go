@@ -0,0 +1,140 @@
+package flagutils_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/parallel"
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type RunFunc func(ctx context.Context, opts flagutils.OptionSet) error
+
+func (f RunFunc) Run(ctx context.Context, opts flagutils.OptionSet) error {
+	return f(ctx, opts)
+}
+
+type Finalizer struct {
+	flagutils.NoOptions
+	finalized bool
+	err       error
+	delay     time.Duration
+}
+
+func (f *Finalizer) Finalize(ctx context.Context, opts flagutils.OptionSet, v flagutils.FinalizationSet) error {
+	time.Sleep(f.delay)
+	f.finalized = true
+	return f.err
+}
+
+var _ = Describe("lifecycle runner", func() {
+	var fin *Finalizer
+	var set flagutils.ExtendableOptionSet
+	var runner *flagutils.LifecycleRunner
+
+	BeforeEach(func() {
+		fin = &Finalizer{}
+		set = flagutils.NewOptionSet(parallel.New(), fin)
+		runner = flagutils.NewLifecycleRunner("test")
+	})
+
+	It("executes the lifecycle", func() {
+		n := 0
+		MustBeSuccessful(runner.Execute(context.Background(), set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			n = parallel.From(opts).Value()
+			return nil
+		}), "-p", "3"))
+		Expect(n).To(Equal(3))
+		Expect(fin.finalized).To(BeTrue())
+	})
+
+	It("reports parse errors", func() {
+		err := runner.Execute(context.Background(), set, RunFunc(nil), "--unknown")
+		Expect(err).To(MatchError("parsing failed: unknown flag: --unknown"))
+		Expect(flagutils.ExitCode(err)).To(Equal(flagutils.EXIT_USAGE))
+		Expect(fin.finalized).To(BeFalse())
+	})
+
+	It("finalizes after validation errors", func() {
+		err := runner.Execute(context.Background(), set, RunFunc(nil), "-p", "-1")
+		Expect(err).To(MatchError("validation failed: invalid degree of parallelism: -1 (--parallel from command line)"))
+		Expect(flagutils.ExitCode(err)).To(Equal(flagutils.EXIT_USAGE))
+		Expect(fin.finalized).To(BeTrue())
+	})
+
+	It("recovers panics", func() {
+		err := runner.Execute(context.Background(), set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			panic("boom")
+		}))
+		Expect(err).To(MatchError("run failed: panic: boom"))
+		Expect(flagutils.ExitCode(err)).To(Equal(flagutils.EXIT_PANIC))
+		Expect(err.(*flagutils.LifecycleError).Panic).To(Equal("boom"))
+		Expect(err.(*flagutils.LifecycleError).Stack).To(ContainSubstring("lifecycle_runner_test.go"))
+		Expect(fin.finalized).To(BeTrue())
+	})
+
+	It("is used by ExecuteLifecycle", func() {
+		err := flagutils.ExecuteLifecycle(context.Background(), "test", set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			panic("boom")
+		}))
+		Expect(err).To(MatchError("run failed: panic: boom"))
+		Expect(flagutils.ExitCode(err)).To(Equal(flagutils.EXIT_PANIC))
+		Expect(fin.finalized).To(BeTrue())
+	})
+
+	It("reports run and finalize errors", func() {
+		fin.err = fmt.Errorf("cleanup failed")
+		err := runner.Execute(context.Background(), set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			return fmt.Errorf("run error")
+		}))
+		Expect(err).To(MatchError("run failed: run error; finalization failed: cleanup failed"))
+		Expect(err).To(MatchError(fin.err))
+		Expect(flagutils.ExitCode(err)).To(Equal(flagutils.EXIT_FAILURE))
+	})
+
+	It("reports finalize errors", func() {
+		fin.err = fmt.Errorf("cleanup failed")
+		err := runner.Execute(context.Background(), set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			return nil
+		}))
+		Expect(err).To(MatchError("finalization failed: cleanup failed"))
+		Expect(flagutils.ExitCode(err)).To(Equal(flagutils.EXIT_FINALIZE))
+	})
+
+	It("finalizes with timeout", func() {
+		fin.delay = time.Second
+		err := runner.WithFinalizeTimeout(10*time.Millisecond).Execute(context.Background(), set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			return nil
+		}))
+		Expect(err).To(MatchError("finalization failed: timeout after 10ms"))
+	})
+
+	It("finalizes on context cancellation", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := runner.Execute(ctx, set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			cancel()
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}))
+		Expect(err).To(MatchError("run failed: context canceled"))
+		Expect(fin.finalized).To(BeTrue())
+	})
+
+	It("abandons a canceled run not returning within the finalize timeout", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		release := make(chan struct{})
+		defer close(release)
+		err := runner.WithFinalizeTimeout(10*time.Millisecond).Execute(ctx, set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			cancel()
+			<-release
+			return nil
+		}))
+		Expect(err).To(MatchError("run failed: context canceled; run abandoned, finalization skipped"))
+		Expect(err.(*flagutils.LifecycleError).Abandoned).To(BeTrue())
+		Expect(fin.finalized).To(BeFalse())
+	})
+})
@@ -0,0 +1,28 @@
+//go:build unix
+
+package flagutils_test
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/mandelsoft/flagutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("lifecycle runner signals", func() {
+	It("finalizes on signal", func() {
+		fin := &Finalizer{}
+		set := flagutils.NewOptionSet(fin)
+
+		err := flagutils.NewLifecycleRunner("test").WithSignals(syscall.SIGHUP).Execute(context.Background(), set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+		Expect(err).To(MatchError("run failed: interrupted by signal hangup"))
+		Expect(flagutils.ExitCode(err)).To(Equal(128 + int(syscall.SIGHUP)))
+		Expect(fin.finalized).To(BeTrue())
+	})
+})
@@ -31,7 +31,11 @@ func (s ValidationSet) Validate(ctx context.Context, opts OptionSet, orig any) e
 		if v, ok := o.(Validatable); ok {
 			if !set.Set[Validatable](s).Has(v) {
 				set.Set[Validatable](s).Add(v)
-				return v.Validate(ctx, opts, s)
+				err := observe(ctx, PHASE_VALIDATE, orig, func(ctx context.Context) error { return v.Validate(ctx, opts, s) })
+				if err != nil {
+					recordValidationFailure(ctx, orig)
+				}
+				return withProvenance(opts, orig, err)
 			}
 			return nil
 		}
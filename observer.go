@@ -0,0 +1,96 @@
+package flagutils
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EventKind describes the kind of a LifecycleEvent.
+type EventKind string
+
+const (
+	EVENT_BEFORE EventKind = "before"
+	EVENT_AFTER  EventKind = "after"
+)
+
+// LifecycleEvent describes the begin or end of a lifecycle phase
+// (Prepare, Validate or Finalize) for an OptionSet or of the
+// execution of the respective lifecycle method of a single object.
+// The lifecycle executors additionally report the parsing of the
+// command line arguments as phase.
+// Events for objects are nested into the events of the phase and
+// of the object forwarding the phase to nested objects.
+type LifecycleEvent struct {
+	Phase Phase
+	Kind  EventKind
+	// Object is the object the lifecycle method is called for.
+	// It is nil for the events of the phase.
+	Object any
+	// Description describes the object (see DescribeOptions).
+	Description string
+	// Duration is the execution time of the phase or method (only for EVENT_AFTER).
+	Duration time.Duration
+	// Error is the error provided by the phase or method (only for EVENT_AFTER).
+	Error error
+}
+
+// LifecycleObserver is notified about LifecycleEvents by the lifecycle
+// functions Prepare, Validate and Finalize. Observers are registered for
+// a context (see WithLifecycleObserver) or are part of the
+// observed OptionSet.
+type LifecycleObserver interface {
+	LifecycleEvent(ctx context.Context, e *LifecycleEvent)
+}
+
+type observersKey struct{}
+
+// WithLifecycleObserver provides a context registering
+// additional LifecycleObservers.
+func WithLifecycleObserver(ctx context.Context, obs ...LifecycleObserver) context.Context {
+	return context.WithValue(ctx, observersKey{}, append(GetLifecycleObservers(ctx), obs...))
+}
+
+// GetLifecycleObservers provides the LifecycleObservers registered for a context.
+func GetLifecycleObservers(ctx context.Context) []LifecycleObserver {
+	list, _ := ctx.Value(observersKey{}).([]LifecycleObserver)
+	return list[:len(list):len(list)]
+}
+
+// observePhase executes a lifecycle phase for an OptionSet. The
+// observers contained in the set are added to the context
+// used for the phase.
+func observePhase(ctx context.Context, phase Phase, opts OptionSet, f func(ctx context.Context) error) error {
+	if obs := Filter[LifecycleObserver](opts); len(obs) > 0 {
+		ctx = WithLifecycleObserver(ctx, obs...)
+	}
+	return observe(ctx, phase, nil, f)
+}
+
+// observe executes the lifecycle method of an object and
+// notifies the observers of the context.
+func observe(ctx context.Context, phase Phase, o any, f func(ctx context.Context) error) error {
+	obs := GetLifecycleObservers(ctx)
+	if len(obs) == 0 {
+		return f(ctx)
+	}
+
+	e := &LifecycleEvent{Phase: phase, Kind: EVENT_BEFORE, Object: o}
+	if opt, ok := o.(Options); ok {
+		e.Description = DescribeOptions(opt)
+	} else if o != nil {
+		e.Description = fmt.Sprintf("%T", o)
+	}
+	for _, ob := range obs {
+		ob.LifecycleEvent(ctx, e)
+	}
+
+	start := time.Now()
+	err := f(ctx)
+
+	e = &LifecycleEvent{Phase: phase, Kind: EVENT_AFTER, Object: o, Description: e.Description, Duration: time.Since(start), Error: err}
+	for _, ob := range obs {
+		ob.LifecycleEvent(ctx, e)
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package flagutils_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+type Recorder struct {
+	flagutils.NoOptions
+	events []string
+}
+
+func (r *Recorder) LifecycleEvent(ctx context.Context, e *flagutils.LifecycleEvent) {
+	s := fmt.Sprintf("%s %s %s", e.Kind, e.Phase, e.Description)
+	if e.Error != nil {
+		s += ": " + e.Error.Error()
+	}
+	r.events = append(r.events, s)
+}
+
+var _ = Describe("lifecycle observers", func() {
+	It("observes nested validations", func() {
+		rec := &Recorder{}
+		set := flagutils.NewOptionSet(&Test2Option{}, &TestOption{})
+		ctx := flagutils.WithLifecycleObserver(context.Background(), rec)
+		MustBeSuccessful(flagutils.Validate(ctx, set, nil))
+		Expect(rec.events).To(Equal([]string{
+			"before validation ",
+			"before validation *flagutils_test.Test2Option",
+			"before validation *flagutils_test.TestOption",
+			"after validation *flagutils_test.TestOption",
+			"after validation *flagutils_test.Test2Option",
+			"after validation ",
+		}))
+	})
+
+	It("observes with observers of the option set", func() {
+		rec := &Recorder{}
+		set := flagutils.NewOptionSet(rec, &TestOption{Flag: true, Err: fmt.Errorf("failed")})
+		MustFailWithMessage(flagutils.Finalize(context.Background(), set, nil), "failed")
+		Expect(rec.events).To(Equal([]string{
+			"before finalization ",
+			"before finalization *flagutils_test.TestOption",
+			"after finalization *flagutils_test.TestOption: failed",
+			"after finalization : failed",
+		}))
+	})
+})
@@ -60,46 +60,66 @@ func get(pv reflect.Value, o any) bool {
 	return false
 }
 
-func retrieveFrom(set OptionSetProvider, pv reflect.Value) bool {
-	if get(pv, set) {
+func retrieveFrom(set OptionSetProvider, pv reflect.Value, key string, instance []string) bool {
+	if matchInstance(key, instance) && get(pv, set) {
 		return true
 	}
 	for o := range set.AsOptionSet().Options {
-		if set, ok := o.(OptionSetProvider); ok {
-			if ok := retrieveFrom(set, pv); ok {
-				return true
-			}
-		} else {
-			if get(pv, o) {
-				return true
-			}
+		if retrieveOption(o, pv, key, instance) {
+			return true
 		}
 	}
 	return false
 }
 
+func retrieveOption(o Options, pv reflect.Value, key string, instance []string) bool {
+	if p, ok := o.(PrefixedInstance); ok {
+		if matchInstance(key, instance) && get(pv, o) {
+			return true
+		}
+		return retrieveOption(p.Unwrap(), pv, PrefixedName(key, p.GetPrefix()), instance)
+	}
+	if set, ok := o.(OptionSetProvider); ok {
+		return retrieveFrom(set, pv, key, instance)
+	}
+	return matchInstance(key, instance) && get(pv, o)
+}
+
+// matchInstance checks the instance key of an Options object
+// against an optional requested instance key.
+func matchInstance(key string, instance []string) bool {
+	return len(instance) == 0 || key == instance[0]
+}
+
 // RetrieveFrom extracts the option for a given target. This might be a
 //   - pointer to a struct implementing the Options interface which
 //     will fill the struct with a copy of the options OR
 //   - a pointer to such a pointer which will be filled with the
 //     pointer to the actual member of the OptionSet.
-func RetrieveFrom(set OptionSetProvider, proto interface{}) bool {
-	return retrieveFrom(set, reflect.ValueOf(proto))
+//
+// Options objects instantiated by a PrefixedOptions object are
+// found, also. An optional instance key restricts the search to
+// the instance with this prefix. The empty key selects options
+// not instantiated with a prefix.
+func RetrieveFrom(set OptionSetProvider, proto interface{}, instance ...string) bool {
+	return retrieveFrom(set, reflect.ValueOf(proto), "", instance)
 }
 
 // GetFrom retrieves an option of type T from the provided OptionSetProvider
 // and returns it. T is typically a pointer to an option struct of type Options.
 // If an interface type is used the first found implementation is returned.
 // To get all options implementing an interface use Filter.
-func GetFrom[T any](set OptionSetProvider) T {
+// An optional instance key selects the instance of a PrefixedOptions
+// object with this prefix (see RetrieveFrom).
+func GetFrom[T any](set OptionSetProvider, instance ...string) T {
 	var r T
-	RetrieveFrom(set, &r)
+	RetrieveFrom(set, &r, instance...)
 	return r
 }
 
-func GetFrom2[T any](set OptionSetProvider) (T, bool) {
+func GetFrom2[T any](set OptionSetProvider, instance ...string) (T, bool) {
 	var r T
-	ok := RetrieveFrom(set, &r)
+	ok := RetrieveFrom(set, &r, instance...)
 	return r, ok
 }
 
@@ -131,24 +151,52 @@ func filter[T any](set OptionSetProvider, result *[]T, check matcher.Matcher[T])
 		}
 	}
 	for o := range set.AsOptionSet().Options {
-		if v, ok := o.(OptionSetProvider); ok {
-			filter[T](v, result, check)
-		} else {
-			if v, ok := o.(T); ok && check(v) {
-				*result = append(*result, v)
-			}
-		}
+		filterOption(o, result, check)
+	}
+}
+
+func filterOption[T any](o Options, result *[]T, check matcher.Matcher[T]) {
+	if v, ok := o.(OptionSetProvider); ok {
+		filter[T](v, result, check)
+		return
+	}
+	if v, ok := o.(T); ok && check(v) {
+		*result = append(*result, v)
+	}
+	if p, ok := o.(PrefixedInstance); ok {
+		filterOption(p.Unwrap(), result, check)
 	}
 }
 
 // Filter extracts elements of type T from the provided OptionSetProvider and returns them as a slice of T.
 // An optional matcher can be used to additionally filter the result.
+// Options objects instantiated by a PrefixedOptions object are included.
 func Filter[T any](set OptionSetProvider, check ...matcher.Matcher[T]) []T {
 	var result []T
 	filter[T](set, &result, matcher.And(check...))
 	return result
 }
 
+// visitInstances calls the visitor for all nested Options objects,
+// which are no option sets, together with their instance key,
+// the (nested) prefix of the wrapping PrefixedOptions objects.
+func visitInstances(set OptionSetProvider, key string, visit func(o Options, key string)) {
+	for o := range set.AsOptionSet().Options {
+		visitInstance(o, key, visit)
+	}
+}
+
+func visitInstance(o Options, key string, visit func(o Options, key string)) {
+	switch v := o.(type) {
+	case OptionSetProvider:
+		visitInstances(v, key, visit)
+	case PrefixedInstance:
+		visitInstance(v.Unwrap(), PrefixedName(key, v.GetPrefix()), visit)
+	default:
+		visit(o, key)
+	}
+}
+
 // Assure expects an ExtendableOptionSet and adds an options object
 // provided by a factory method, if it is not yet present.
 // Optional matchers can be used to apply additional filters,
@@ -17,6 +17,53 @@ type MappingProvider[I any, F FieldProvider] = internal.MappingProvider[I, F]
 ////////////////////////////////////////////////////////////////////////////////
 
 type OutputFactory[I any] = internal.OutputFactory[I]
+type ParameterizedOutputFactory[I any] = internal.ParameterizedOutputFactory[I]
 type Output[I any] = internal.Output[I]
 
 type OutputsFactory[I any] = internal.OutputsFactory[I]
+type ParameterizedOutputsFactory[I any] = internal.ParameterizedOutputsFactory[I]
+type PresetOutputsFactory[I any] = internal.PresetOutputsFactory[I]
+
+////////////////////////////////////////////////////////////////////////////////
+
+type Aggregation = internal.Aggregation
+
+const (
+	TOTAL   = internal.TOTAL
+	AVERAGE = internal.AVERAGE
+)
+
+type SummaryField = internal.SummaryField
+type Summary = internal.Summary
+type Summarizer = internal.Summarizer
+
+var (
+	Total         = internal.Total
+	Average       = internal.Average
+	NewSummarizer = internal.NewSummarizer
+	FormatNumber  = internal.FormatNumber
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+type ErrorProvider = internal.ErrorProvider
+type ElementsError = internal.ElementsError
+type ErrorReporter = internal.ErrorReporter
+
+var (
+	GetError         = internal.GetError
+	NewErrorReporter = internal.NewErrorReporter
+	GetErrorReporter = internal.GetErrorReporter
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+type RawFieldProvider = internal.RawFieldProvider
+type Value = internal.Value
+type Values = internal.Values
+
+var (
+	GetRawFields = internal.GetRawFields
+	CompareRaw   = internal.CompareRaw
+	Plain        = internal.Plain
+)
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"github.com/mandelsoft/streaming/chain"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/errorpolicy"
+)
+
+// ErrorProvider is implemented by elements describing
+// a failure. Such errors are reported by the outputs
+// (see ErrorReporter).
+type ErrorProvider = errorpolicy.ErrorProvider
+
+// ElementsError is the error provided by an output if elements
+// failed and the exit status policy requests a failure.
+type ElementsError = errorpolicy.ElementsError
+
+// ErrorReporter collects the errors of processed elements.
+// The reporter for a run is provided by the error policy option
+// (see errorpolicy.GetReporter).
+type ErrorReporter = errorpolicy.Reporter
+
+// GetError provides the error of an element, if
+// it implements the ErrorProvider interface.
+func GetError(e any) error {
+	return errorpolicy.GetError(e)
+}
+
+func NewErrorReporter(status int) *ErrorReporter {
+	return errorpolicy.NewReporter(status)
+}
+
+// GetErrorReporter provides the ErrorReporter for an output.
+// This is the reporter shared by all outputs of a run provided by the error
+// policy option, which is reported once by the output of the output options.
+// Without such an option, a new reporter is provided, which must be
+// reported by the output itself (indicated by the second result).
+func GetErrorReporter(opts flagutils.OptionSetProvider) (*ErrorReporter, bool) {
+	if r := errorpolicy.GetReporter(opts); r != nil {
+		return r, false
+	}
+	return NewErrorReporter(0), true
+}
+
+// AddErrorChain adds a step to a chain recording the
+// errors of the elements.
+func AddErrorChain[I, O any](r *ErrorReporter, c chain.Chain[I, O]) chain.Chain[I, O] {
+	return errorpolicy.AddErrorChain(r, c)
+}
@@ -50,6 +50,20 @@ type OutputFactory[I any] interface {
 	Create(ctx context.Context, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (Output[I], error)
 }
 
+// ParameterizedOutputFactory is an OutputFactory for output modes
+// accepting a parameter (like a column list or a template).
+// Instead of Create and GetFieldNames, the variants with a parameter
+// are used. The parameter is empty, if not given.
+type ParameterizedOutputFactory[I any] interface {
+	OutputFactory[I]
+	// GetParameterHelp provides a short description of the
+	// parameter syntax (like <column>:<field>,...) used for the flag description.
+	GetParameterHelp() string
+	ValidateParameter(param string) error
+	GetParameterFieldNames(param, stage string) []string
+	CreateWithParameter(ctx context.Context, param string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (Output[I], error)
+}
+
 type Output[I any] interface {
 	Process(ctx context.Context, specs ElementSpecs, src streaming.SourceFactory[ElementSpecs, I]) (Result, error)
 }
@@ -64,3 +78,24 @@ type OutputsFactory[I any] interface {
 	GetFieldNames(mode, stage string) []string
 	CreateOutput(ctx context.Context, mode string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (Output[I], error)
 }
+
+// ParameterizedOutputsFactory is an optional interface for an OutputsFactory
+// supporting parameterized output modes (see ParameterizedOutputFactory).
+// Without it, a mode parameter is rejected.
+type ParameterizedOutputsFactory[I any] interface {
+	OutputsFactory[I]
+	// GetParameterHelp provides the parameter help for
+	// a parameterized output mode.
+	GetParameterHelp(mode string) (string, bool)
+
+	GetParameterFieldNames(mode, param, stage string) []string
+	CreateParameterizedOutput(ctx context.Context, mode, param string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (Output[I], error)
+}
+
+// PresetOutputsFactory is an optional interface for an OutputsFactory
+// offering built-in option presets (see package preset).
+type PresetOutputsFactory[I any] interface {
+	OutputsFactory[I]
+	flagutils.PresetProvider
+	AddPreset(name, desc string, args ...string) OutputsFactory[I]
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Aggregation describes how the values of a field are summarized.
+type Aggregation string
+
+const (
+	TOTAL   Aggregation = "total"
+	AVERAGE Aggregation = "average"
+)
+
+// SummaryField declares a numeric field summarized
+// by an aggregation.
+type SummaryField struct {
+	Name        string
+	Aggregation Aggregation
+}
+
+// Total declares the total of a field.
+func Total(name string) SummaryField {
+	return SummaryField{name, TOTAL}
+}
+
+// Average declares the average of a field.
+func Average(name string) SummaryField {
+	return SummaryField{name, AVERAGE}
+}
+
+// Summary describes the summary of an output.
+type Summary struct {
+	Count    int                `json:"count" yaml:"count"`
+	Totals   map[string]float64 `json:"totals,omitempty" yaml:"totals,omitempty"`
+	Averages map[string]float64 `json:"averages,omitempty" yaml:"averages,omitempty"`
+}
+
+// Get provides the aggregated value for a summary field.
+func (s *Summary) Get(f SummaryField) (float64, bool) {
+	var m map[string]float64
+	switch f.Aggregation {
+	case TOTAL:
+		m = s.Totals
+	case AVERAGE:
+		m = s.Averages
+	}
+	v, ok := m[f.Name]
+	return v, ok
+}
+
+// Summarizer calculates a Summary for a sequence of elements.
+// Non-numeric field values are ignored.
+type Summarizer struct {
+	fields []SummaryField
+	sums   []float64
+	counts []int
+	count  int
+}
+
+func NewSummarizer(fields ...SummaryField) *Summarizer {
+	return &Summarizer{
+		fields: fields,
+		sums:   make([]float64, len(fields)),
+		counts: make([]int, len(fields)),
+	}
+}
+
+// Add adds an element described by a function providing the
+// value of a field by its name.
+func (s *Summarizer) Add(value func(name string) any) {
+	s.count++
+	for i, f := range s.fields {
+		if v, ok := Number(value(f.Name)); ok {
+			s.sums[i] += v
+			s.counts[i]++
+		}
+	}
+}
+
+func (s *Summarizer) Summary() *Summary {
+	r := &Summary{Count: s.count}
+	for i, f := range s.fields {
+		switch f.Aggregation {
+		case TOTAL:
+			if r.Totals == nil {
+				r.Totals = map[string]float64{}
+			}
+			r.Totals[f.Name] = s.sums[i]
+		case AVERAGE:
+			if s.counts[i] == 0 {
+				continue
+			}
+			if r.Averages == nil {
+				r.Averages = map[string]float64{}
+			}
+			r.Averages[f.Name] = s.sums[i] / float64(s.counts[i])
+		}
+	}
+	return r
+}
+
+// Number converts a field value to a number.
+func Number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// FormatNumber formats an aggregated value.
+func FormatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
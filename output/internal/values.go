@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RawFieldProvider is a FieldProvider additionally providing
+// the raw field values. They are used for sorting and
+// the raw output (see package humanize).
+type RawFieldProvider interface {
+	FieldProvider
+	GetRawFields() []string
+}
+
+// GetRawFields provides the raw fields of a FieldProvider.
+// For providers not implementing RawFieldProvider, the
+// regular fields are used.
+func GetRawFields(p FieldProvider) []string {
+	if r, ok := p.(RawFieldProvider); ok {
+		return r.GetRawFields()
+	}
+	return p.GetFields()
+}
+
+// CompareRaw compares raw field values. Numbers are compared
+// numerically, other values as strings.
+func CompareRaw(a, b string) int {
+	fa, erra := strconv.ParseFloat(a, 64)
+	fb, errb := strconv.ParseFloat(b, 64)
+	if erra == nil && errb == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// Value is a field value with a human-readable and
+// a raw representation.
+type Value struct {
+	Raw   string
+	Human string
+}
+
+// Plain provides a Value using the same representation
+// for both variants.
+func Plain(s string) Value {
+	return Value{s, s}
+}
+
+// Values is a field list of Values.
+type Values []Value
+
+var _ RawFieldProvider = Values(nil)
+var _ ExtendedFieldProvider = (*Values)(nil)
+
+func (v Values) GetFields() []string {
+	fields := make([]string, len(v))
+	for i, e := range v {
+		fields[i] = e.Human
+	}
+	return fields
+}
+
+func (v Values) GetRawFields() []string {
+	fields := make([]string, len(v))
+	for i, e := range v {
+		fields[i] = e.Raw
+	}
+	return fields
+}
+
+func (v *Values) InsertFields(i int, s ...string) {
+	values := make(Values, len(s))
+	for j, e := range s {
+		values[j] = Plain(e)
+	}
+	*v = slices.Insert(*v, i, values...)
+}
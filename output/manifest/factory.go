@@ -2,21 +2,36 @@ package manifest
 
 import (
 	"context"
+	"slices"
+
 	"github.com/mandelsoft/flagutils/closure"
 	"github.com/mandelsoft/streaming/chain"
 
 	"github.com/mandelsoft/flagutils"
 	output "github.com/mandelsoft/flagutils/output/internal"
+	"github.com/mandelsoft/flagutils/summary"
 )
 
 type OutputFactory[I any] struct {
 	formatter Formatter
+	summary   []output.SummaryField
 }
 
 var _ output.OutputFactory[int] = (*OutputFactory[int])(nil)
 
 func NewOutputFactory[I any](formatter Formatter) *OutputFactory[I] {
-	return &OutputFactory[I]{formatter}
+	return &OutputFactory[I]{formatter: formatter}
+}
+
+// WithSummary provides a copy of the factory declaring the
+// aggregated fields of the summary requested by the summary option.
+// The field names are matched case-insensitively against the top-level
+// fields of the manifests, this way the same declarations as for
+// the table output can be used.
+func (o *OutputFactory[I]) WithSummary(fields ...output.SummaryField) *OutputFactory[I] {
+	c := *o
+	c.summary = slices.Clone(fields)
+	return &c
 }
 
 func (o *OutputFactory[I]) GetFieldNames(string) []string {
@@ -25,13 +40,22 @@ func (o *OutputFactory[I]) GetFieldNames(string) []string {
 
 func (o *OutputFactory[I]) Create(ctx context.Context, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (output.Output[I], error) {
 	c := closure.AddExplodeChain(opts, chain.New[I]())
-	return output.NewOutput[I, Manifest](chain.AddMap[Manifest](c, mapToManifest), &Factory{o.formatter}), nil
+	errs, report := output.GetErrorReporter(opts)
+	return output.NewOutput[I, Manifest](chain.AddMap[Manifest](c, mapToManifest), &Factory{
+		formatter:    o.formatter,
+		fields:       o.summary,
+		summary:      summary.Requested(opts),
+		errors:       errs,
+		reportErrors: report,
+	}), nil
 }
 
-func AddManifestOutputs[I any](out output.OutputsFactory[I]) output.OutputsFactory[I] {
-	out.Add("yaml", NewYAMLFactory[I](false))
-	out.Add("YAML", NewYAMLFactory[I](true))
-	out.Add("json", NewJSONFactory[I](false))
-	out.Add("JSON", NewJSONFactory[I](true))
+// AddManifestOutputs adds the manifest output modes. Optionally,
+// the aggregated fields of the summary can be declared (see WithSummary).
+func AddManifestOutputs[I any](out output.OutputsFactory[I], summary ...output.SummaryField) output.OutputsFactory[I] {
+	out.Add("yaml", NewOutputFactory[I](NewYAML(false)).WithSummary(summary...))
+	out.Add("YAML", NewOutputFactory[I](NewYAML(true)).WithSummary(summary...))
+	out.Add("json", NewOutputFactory[I](NewJSON(false)).WithSummary(summary...))
+	out.Add("JSON", NewOutputFactory[I](NewJSON(true)).WithSummary(summary...))
 	return out
 }
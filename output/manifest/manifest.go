@@ -13,6 +13,27 @@ type Formatter interface {
 	Format(ctx context.Context, values []Manifest) error
 }
 
+// MetadataFormatter is an optional interface for a Formatter
+// able to include a summary (see package summary) and the
+// errors of the elements (see output.ErrorProvider).
+type MetadataFormatter interface {
+	Formatter
+	FormatWithMetadata(ctx context.Context, values []Manifest, meta *Metadata) error
+}
+
+// Metadata describes the information shown beside the items.
+type Metadata struct {
+	Summary *output.Summary `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Errors  []ElementError  `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// ElementError describes the error of the element
+// with the given index in the item list.
+type ElementError struct {
+	Index int    `json:"index" yaml:"index"`
+	Error string `json:"error" yaml:"error"`
+}
+
 type Manifest interface {
 	AsManifest() interface{}
 }
@@ -20,11 +41,17 @@ type Manifest interface {
 ////////////////////////////////////////////////////////////////////////////////
 
 type ItemList struct {
-	Items []interface{} `json:"items"`
+	Items   []interface{}   `json:"items"`
+	Summary *output.Summary `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Errors  []ElementError  `json:"errors,omitempty" yaml:"errors,omitempty"`
 }
 
-func format(ctx context.Context, values []Manifest, formatter func(data any) ([]byte, error)) ([]byte, error) {
+func format(ctx context.Context, values []Manifest, meta *Metadata, formatter func(data any) ([]byte, error)) ([]byte, error) {
 	items := &ItemList{}
+	if meta != nil {
+		items.Summary = meta.Summary
+		items.Errors = meta.Errors
+	}
 	for _, m := range values {
 		items.Items = append(items.Items, m.AsManifest())
 	}
@@ -37,7 +64,7 @@ type YAML struct {
 	docs bool
 }
 
-var _ Formatter = (*YAML)(nil)
+var _ MetadataFormatter = (*YAML)(nil)
 
 func NewYAML(docs bool) *YAML {
 	return &YAML{docs}
@@ -48,8 +75,14 @@ func NewYAMLFactory[I any](docs bool) output.OutputFactory[I] {
 }
 
 func (f *YAML) Format(ctx context.Context, values []Manifest) error {
+	return f.FormatWithMetadata(ctx, values, nil)
+}
+
+// FormatWithMetadata shows the metadata as fields of the item list or
+// as separate final document.
+func (f *YAML) FormatWithMetadata(ctx context.Context, values []Manifest, meta *Metadata) error {
 	if f.docs {
-		d, err := format(ctx, values, yaml.Marshal)
+		d, err := format(ctx, values, meta, yaml.Marshal)
 		if err != nil {
 			return err
 		}
@@ -70,6 +103,14 @@ func (f *YAML) Format(ctx context.Context, values []Manifest) error {
 				return err
 			}
 		}
+		if meta != nil {
+			d, err := yaml.Marshal(meta)
+			if err != nil {
+				return err
+			}
+			_, err = out.Printf(ctx, "---\n%s", d)
+			return err
+		}
 	}
 	return nil
 }
@@ -80,7 +121,7 @@ type JSON struct {
 	pretty bool
 }
 
-var _ Formatter = (*JSON)(nil)
+var _ MetadataFormatter = (*JSON)(nil)
 
 func NewJSON(pretty bool) *JSON {
 	return &JSON{pretty}
@@ -91,7 +132,12 @@ func NewJSONFactory[I any](pretty bool) output.OutputFactory[I] {
 }
 
 func (f *JSON) Format(ctx context.Context, values []Manifest) error {
-	d, err := format(ctx, values, json.Marshal)
+	return f.FormatWithMetadata(ctx, values, nil)
+}
+
+// FormatWithMetadata shows the metadata as fields of the item list.
+func (f *JSON) FormatWithMetadata(ctx context.Context, values []Manifest, meta *Metadata) error {
+	d, err := format(ctx, values, meta, json.Marshal)
 	if err != nil {
 		return err
 	}
@@ -1,9 +1,14 @@
 package manifest
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"strings"
+
 	output "github.com/mandelsoft/flagutils/output/internal"
 	"github.com/mandelsoft/flagutils/utils/out"
+	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/iterutils"
 	"github.com/mandelsoft/streaming"
 	"iter"
@@ -13,6 +18,12 @@ import (
 // because no state is required.
 type Factory struct {
 	formatter Formatter
+	fields    []output.SummaryField
+	summary   bool
+	errors    *output.ErrorReporter
+	// reportErrors is set if the errors are not reported by
+	// the output options (see output.GetErrorReporter).
+	reportErrors bool
 }
 
 var _ streaming.ProcessorFactory[output.ElementSpecs, output.Result, Manifest] = (*Factory)(nil)
@@ -32,8 +43,64 @@ func (p *Factory) Process(ctx context.Context, i iter.Seq[Manifest]) (int, error
 		out.Print(ctx, "no elements found\n")
 		return 0, nil
 	}
-	p.formatter.Format(ctx, d)
-	return len(d), nil
+
+	meta := &Metadata{}
+	if p.summary {
+		meta.Summary = summarize(d, p.fields)
+	}
+	for i, m := range d {
+		e := element(m)
+		p.errors.Check(e)
+		if err := output.GetError(e); err != nil {
+			meta.Errors = append(meta.Errors, ElementError{i, err.Error()})
+		}
+	}
+
+	var err error
+	if f, ok := p.formatter.(MetadataFormatter); ok && (meta.Summary != nil || meta.Errors != nil) {
+		err = f.FormatWithMetadata(ctx, d, meta)
+	} else {
+		err = p.formatter.Format(ctx, d)
+	}
+	if p.reportErrors {
+		err = errors.Join(err, p.errors.Report(ctx))
+	}
+	return len(d), err
+}
+
+func summarize(values []Manifest, fields []output.SummaryField) *output.Summary {
+	s := output.NewSummarizer(fields...)
+	for _, m := range values {
+		data, _ := Data(m)
+		fields, _ := data.(map[string]any)
+		s.Add(func(name string) any {
+			for k, v := range fields {
+				if strings.EqualFold(k, name) {
+					return v
+				}
+			}
+			return nil
+		})
+	}
+	return s.Summary()
+}
+
+// Data provides the generic JSON representation of the manifest
+// of an element. Elements not implementing Manifest are used as
+// manifest. Numbers are provided as json.Number.
+func Data(e any) (any, error) {
+	if m, ok := e.(Manifest); ok {
+		e = m.AsManifest()
+	}
+	d, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	var data any
+	dec := json.NewDecoder(bytes.NewReader(d))
+	dec.UseNumber()
+	err = dec.Decode(&data)
+	return data, err
 }
 
 type wrapper struct {
@@ -44,6 +111,14 @@ func (w *wrapper) AsManifest() any {
 	return w.e
 }
 
+// element provides the element a manifest is created for.
+func element(m Manifest) any {
+	if w, ok := m.(*wrapper); ok {
+		return w.e
+	}
+	return m
+}
+
 func mapToManifest[I any](in I) Manifest {
 	if m, ok := any(in).(Manifest); ok {
 		return m
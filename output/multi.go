@@ -0,0 +1,146 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/streaming"
+
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// ModeSpec describes a requested output mode with an
+// optional parameter or an optional destination file.
+type ModeSpec struct {
+	Mode string
+	// Parameter is the parameter for a parameterized output
+	// mode (see ParameterizedOutputFactory).
+	Parameter string
+	// File is the destination file. If empty, the output is written to
+	// the output context (see package utils/out).
+	File string
+}
+
+// ParseModeSpec parses a mode spec.
+// The spec <mode>=<parameter> or <mode>:<parameter> describes a mode
+// parameter and <mode>@<file> a destination file. The meaning
+// is determined by the first separator, regardless of the mode.
+// Because parameters may contain any character, a spec cannot describe
+// a parameter and a file. The output of a parameterized mode can be written
+// to a file with the destination options (see package destination).
+func ParseModeSpec(s string) ModeSpec {
+	i := strings.IndexAny(s, ":=@")
+	if i < 0 {
+		return ModeSpec{Mode: s}
+	}
+	spec := ModeSpec{Mode: s[:i]}
+	if s[i] == '@' {
+		spec.File = s[i+1:]
+	} else {
+		spec.Parameter = s[i+1:]
+	}
+	return spec
+}
+
+func (s ModeSpec) String() string {
+	switch {
+	case s.Parameter != "":
+		return s.Mode + ":" + s.Parameter
+	case s.File != "":
+		return s.Mode + "@" + s.File
+	}
+	return s.Mode
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// fileOutput writes the output to a file. The file is replaced
+// atomically after a successful processing.
+type fileOutput[I any] struct {
+	Output[I]
+	path string
+}
+
+func (o *fileOutput[I]) Process(ctx context.Context, specs ElementSpecs, src streaming.SourceFactory[ElementSpecs, I]) (Result, error) {
+	temp, err := os.CreateTemp(filepath.Dir(o.path), "."+filepath.Base(o.path)+".*")
+	if err != nil {
+		return 0, fmt.Errorf("cannot create output file: %w", err)
+	}
+
+	r, err := o.Output.Process(out.With(ctx, out.New(temp, nil)), specs, src)
+	cerr := temp.Close()
+	if err == nil && cerr == nil {
+		mode := os.FileMode(0o644)
+		if fi, serr := os.Stat(o.path); serr == nil {
+			mode = fi.Mode().Perm()
+		}
+		err = os.Chmod(temp.Name(), mode)
+		if err == nil {
+			err = os.Rename(temp.Name(), o.path)
+		}
+		if err == nil {
+			return r, nil
+		}
+		err = fmt.Errorf("cannot write output file: %w", err)
+	}
+	return r, errors.Join(err, cerr, os.Remove(temp.Name()))
+}
+
+// multiOutput executes the source factory once and feeds the
+// elements into the processing of all outputs.
+// The elements are buffered and the outputs are processed sequentially,
+// one after the other. This way, outputs written to the output context
+// are not interleaved and outputs sharing other options (like
+// progress or error reporters) are not executed concurrently.
+// The memory required for the elements grows with the size of the source.
+type multiOutput[I any] struct {
+	specs   []ModeSpec
+	outputs []Output[I]
+	errors  *ErrorReporter
+}
+
+func (o *multiOutput[I]) Process(ctx context.Context, specs ElementSpecs, src streaming.SourceFactory[ElementSpecs, I]) (Result, error) {
+	s, err := src.Elements(specs)
+	if err != nil {
+		return 0, err
+	}
+	elems := slices.Collect(s)
+	replay := streaming.SourceFactoryFunc[ElementSpecs, I](func(ElementSpecs) (iter.Seq[I], error) {
+		return slices.Values(elems), nil
+	})
+
+	var result Result
+	var list []error
+	for i, out := range o.outputs {
+		if i > 0 {
+			// the elements are checked by every output.
+			o.errors.NextPass()
+		}
+		r, err := out.Process(ctx, specs, replay)
+		if i == 0 {
+			result = r
+		}
+		if err != nil {
+			list = append(list, fmt.Errorf("output %s: %w", o.specs[i], err))
+		}
+	}
+	return result, errors.Join(list...)
+}
+
+// reportingOutput reports the errors of the elements
+// recorded by the outputs of a run.
+type reportingOutput[I any] struct {
+	Output[I]
+	errors *ErrorReporter
+}
+
+func (o *reportingOutput[I]) Process(ctx context.Context, specs ElementSpecs, src streaming.SourceFactory[ElementSpecs, I]) (Result, error) {
+	r, err := o.Output.Process(ctx, specs, src)
+	return r, errors.Join(err, o.errors.Report(ctx))
+}
@@ -1,49 +1,121 @@
 package output
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/mandelsoft/goutils/set"
+	"github.com/spf13/pflag"
+
 	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/errorpolicy"
+	"github.com/mandelsoft/flagutils/progress"
 )
 
 func From[I any](opts flagutils.OptionSetProvider) *Options[I] {
 	return flagutils.GetFrom[*Options[I]](opts)
 }
 
+// Options selects the output modes. Multiple modes may be requested
+// (-o table -o json@out.json). Every mode spec may optionally
+// describe a destination file (<mode>@<file>) or a parameter for
+// parameterized modes (see ParseModeSpec), but not both.
+// If multiple modes are requested, the elements of a single source
+// execution are buffered and processed by all requested outputs
+// sequentially.
+// The value of the option is the first requested mode spec.
 type Options[I any] struct {
 	flagutils.SimpleOption[string, *Options[I]]
 	// config
 	factory OutputsFactory[I]
+	// specs are all requested mode specs.
+	specs []string
 
 	// out
 	output Output[I]
 }
 
 var (
-	_ flagutils.Options     = (*Options[int])(nil)
-	_ FieldNameProvider     = (*Options[int])(nil)
-	_ flagutils.Validatable = (*Options[int])(nil)
+	_ flagutils.Options        = (*Options[int])(nil)
+	_ FieldNameProvider        = (*Options[int])(nil)
+	_ flagutils.Validatable    = (*Options[int])(nil)
+	_ flagutils.PresetProvider = (*Options[int])(nil)
 )
 
 func New[I any](out OutputsFactory[I]) *Options[I] {
 	o := &Options[I]{factory: out}
-	o.SimpleOption = flagutils.NewSimpleOption[string](o, "", "mode", "o", o.description("output mode (%s)"))
+	o.SimpleOption = flagutils.NewSimpleOptionWithSetter[string](o, o.modesVarP, "", "mode", "o", o.description("output mode (%s), modes without parameter optionally followed by @<file>"))
 	return o
 }
 
+// modesVarP is the flag setter for the mode specs.
+func (o *Options[I]) modesVarP(fs *pflag.FlagSet, p *string, name, shorthand string, _ string, usage string) {
+	fs.VarP(&modesValue{first: p, specs: &o.specs}, name, shorthand, usage)
+}
+
+// AddFlags adds the mode flag offering the known modes as choices
+// for interactive input. The choices are open, because
+// mode specs may describe a parameter or a file.
+func (o *Options[I]) AddFlags(fs *pflag.FlagSet) {
+	o.SimpleOption.AddFlags(fs)
+	name, _ := o.GetNames()
+	if f := fs.Lookup(name); f != nil {
+		flagutils.SetFlagOpenChoices(f, o.GetModes()...)
+	}
+}
+
+// Set sets a single mode spec.
+func (o *Options[I]) Set(spec string) *Options[I] {
+	return o.SetModeSpecs(spec)
+}
+
+// SetModeSpecs sets the requested mode specs.
+func (o *Options[I]) SetModeSpecs(specs ...string) *Options[I] {
+	o.specs = slices.Clone(specs)
+	first := ""
+	if len(specs) > 0 {
+		first = specs[0]
+	}
+	return o.SimpleOption.Set(first)
+}
+
 func (o *Options[I]) WithDescription(s string) *Options[I] {
 	return o.SimpleOption.WithDescription(o.description(s))
 }
 
+// description provides the flag description. Parameterized
+// modes are described together with their parameter syntax.
 func (o *Options[I]) description(msg string) string {
-	keys := o.factory.GetModes()
+	var keys []string
+	for _, m := range o.factory.GetModes() {
+		if help, ok := GetParameterHelp(o.factory, m); ok {
+			m += "=" + help
+		}
+		keys = append(keys, m)
+	}
 	return fmt.Sprintf(msg, strings.Join(keys, ", "))
 }
 
+// GetMode provides the first requested output mode.
 func (o *Options[I]) GetMode() string {
-	return o.Value()
+	return o.GetModeSpecs()[0].Mode
+}
+
+// GetModeSpecs provides the requested output modes.
+// Without explicitly requested modes, the default mode ("") is used.
+func (o *Options[I]) GetModeSpecs() []ModeSpec {
+	var specs []ModeSpec
+	for _, s := range o.specs {
+		specs = append(specs, ParseModeSpec(s))
+	}
+	if len(specs) == 0 {
+		specs = []ModeSpec{{}}
+	}
+	return specs
 }
 
 func (o *Options[I]) GetOutputs() OutputsFactory[I] {
@@ -54,15 +126,143 @@ func (o *Options[I]) GetOutput() Output[I] {
 	return o.output
 }
 
+func (o *Options[I]) GetModes() []string {
+	return o.factory.GetModes()
+}
+
+// GetParameterHelp provides the parameter syntax of a parameterized mode.
+func (o *Options[I]) GetParameterHelp(mode string) (string, bool) {
+	return GetParameterHelp(o.factory, mode)
+}
+
+// GetPresets provides the presets of the OutputsFactory,
+// if it implements flagutils.PresetProvider.
+func (o *Options[I]) GetPresets() []flagutils.Preset {
+	if p, ok := o.factory.(flagutils.PresetProvider); ok {
+		return p.GetPresets()
+	}
+	return nil
+}
+
+// GetFieldNames provides the field names supported by all
+// requested output modes providing field names.
 func (o *Options[I]) GetFieldNames(stage string) []string {
-	return o.factory.GetFieldNames(o.Value(), stage)
+	var names []string
+	for _, s := range o.GetModeSpecs() {
+		n := GetFieldNames(o.factory, s.Mode, s.Parameter, stage)
+		if n == nil {
+			continue
+		}
+		if names == nil {
+			names = n
+		} else {
+			names = slices.DeleteFunc(slices.Clone(names), func(e string) bool { return !slices.Contains(n, e) })
+		}
+	}
+	return names
 }
 
+// Validate creates the outputs for the requested modes. The errors
+// of the elements recorded by the outputs with the reporter of the
+// error policy option (see errorpolicy.GetReporter) are reported once
+// after all outputs have finished.
 func (o *Options[I]) Validate(ctx context.Context, opts flagutils.OptionSet, v flagutils.ValidationSet) error {
-	of, err := o.factory.CreateOutput(ctx, o.Value(), opts, v)
-	if err != nil {
-		return err
+	specs := o.GetModeSpecs()
+	files := set.New[string]()
+
+	// the error policy and progress options are optional. If given, they
+	// are validated first, because the outputs use the reporters provided
+	// by their validation. Otherwise, every output reports the errors of
+	// its elements on its own and no progress is shown.
+	var errs *ErrorReporter
+	if policy := flagutils.GetFrom[*errorpolicy.Options](opts); policy != nil {
+		if err := v.Validate(ctx, opts, policy); err != nil {
+			return err
+		}
+		errs = policy.GetReporter()
+	}
+	if p := flagutils.GetFrom[*progress.Options](opts); p != nil {
+		if err := v.Validate(ctx, opts, p); err != nil {
+			return err
+		}
+	}
+
+	multi := &multiOutput[I]{specs: specs, errors: errs}
+	for _, s := range specs {
+		of, err := CreateOutput(ctx, o.factory, s.Mode, s.Parameter, opts, v)
+		if err != nil {
+			return err
+		}
+		if s.File != "" {
+			if files.Has(s.File) {
+				return fmt.Errorf("output file %q used for multiple modes", s.File)
+			}
+			files.Add(s.File)
+			of = &fileOutput[I]{of, s.File}
+		}
+		multi.outputs = append(multi.outputs, of)
+	}
+	if len(multi.outputs) == 1 {
+		o.output = multi.outputs[0]
+	} else {
+		o.output = multi
+	}
+	if errs != nil {
+		o.output = &reportingOutput[I]{o.output, errs}
 	}
-	o.output = of
 	return nil
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+// modesValue is the flag value for the mode specs. Like for a
+// stringArray, every occurrence of the flag describes a single mode
+// spec, because specs may contain commas (like custom-columns=A:.a,B:.b).
+// The first spec is provided as string value.
+type modesValue struct {
+	first   *string
+	specs   *[]string
+	changed bool
+}
+
+var _ pflag.SliceValue = (*modesValue)(nil)
+
+func (v *modesValue) Set(s string) error {
+	if !v.changed {
+		*v.specs = nil
+		v.changed = true
+	}
+	return v.Append(s)
+}
+
+func (v *modesValue) Append(s string) error {
+	*v.specs = append(*v.specs, s)
+	*v.first = (*v.specs)[0]
+	return nil
+}
+
+func (v *modesValue) Replace(list []string) error {
+	*v.specs = slices.Clone(list)
+	*v.first = ""
+	if len(list) > 0 {
+		*v.first = list[0]
+	}
+	return nil
+}
+
+func (v *modesValue) GetSlice() []string {
+	return slices.Clone(*v.specs)
+}
+
+func (v *modesValue) Type() string {
+	return "string"
+}
+
+func (v *modesValue) String() string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	//nolint: errcheck // writing to buffer
+	w.Write(*v.specs)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,207 @@
+package output_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/streaming"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/prompt"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+type source struct {
+	count int
+}
+
+func (s *source) Elements(output.ElementSpecs) (iter.Seq[int], error) {
+	s.count++
+	return slices.Values([]int{1, 2}), nil
+}
+
+// prefixFactory is a parameterized output mode printing
+// every element with a prefix given as parameter.
+type prefixFactory struct{}
+
+var _ output.ParameterizedOutputFactory[int] = (*prefixFactory)(nil)
+
+func (f *prefixFactory) GetFieldNames(stage string) []string {
+	return nil
+}
+
+func (f *prefixFactory) Create(ctx context.Context, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (output.Output[int], error) {
+	return f.CreateWithParameter(ctx, "", opts, v)
+}
+
+func (f *prefixFactory) GetParameterHelp() string {
+	return "<prefix>"
+}
+
+func (f *prefixFactory) ValidateParameter(param string) error {
+	if param == "" {
+		return fmt.Errorf("prefix required")
+	}
+	return nil
+}
+
+func (f *prefixFactory) GetParameterFieldNames(param, stage string) []string {
+	return []string{param}
+}
+
+func (f *prefixFactory) CreateWithParameter(ctx context.Context, param string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (output.Output[int], error) {
+	return &prefixOutput{param}, nil
+}
+
+type prefixOutput struct {
+	prefix string
+}
+
+func (o *prefixOutput) Process(ctx context.Context, specs output.ElementSpecs, src streaming.SourceFactory[output.ElementSpecs, int]) (output.Result, error) {
+	s, err := src.Elements(specs)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for e := range s {
+		out.Printf(ctx, "%s%d\n", o.prefix, e)
+		n++
+	}
+	return n, nil
+}
+
+// plainOutputs is an OutputsFactory without support
+// for parameterized modes.
+type plainOutputs struct {
+	output.OutputsFactory[int]
+}
+
+var _ = Describe("output options", func() {
+	var dir string
+	var src *source
+	var opts *output.Options[int]
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		src = &source{}
+		opts = output.New(output.NewOutputsFactory[int]().AddManifestOutputs())
+	})
+
+	process := func(ctx context.Context, set flagutils.OptionSet) error {
+		_, err := output.From[int](set).GetOutput().Process(ctx, nil, src)
+		return err
+	}
+
+	It("processes multiple modes", func() {
+		path := filepath.Join(dir, "out.json")
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), flagutilstest.RunnerFunc(process), "-o", "yaml", "-o", "json@"+path)
+		Expect(r.Error()).To(Succeed())
+		Expect(src.count).To(Equal(1))
+		Expect(r.Stdout).To(Equal("---\n1\n---\n2\n"))
+		Expect(os.ReadFile(path)).To(Equal([]byte(`{"items":[1,2]}`)))
+		Expect(opts.GetMode()).To(Equal("yaml"))
+		Expect(opts.GetModeSpecs()).To(Equal([]output.ModeSpec{{Mode: "yaml"}, {Mode: "json", File: path}}))
+	})
+
+	It("keeps the single mode API", func() {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		opts.Set("json").AddFlags(fs)
+		Expect(fs.FlagUsages()).To(ContainSubstring("-o, --mode string "))
+		Expect(opts.Value()).To(Equal("json"))
+		Expect(opts.GetModeSpecs()).To(Equal([]output.ModeSpec{{Mode: "json"}}))
+
+		Expect(flagutils.Parse(fs, []string{"-o", "yaml", "-o", "custom=a,b"})).To(Succeed())
+		Expect(opts.Value()).To(Equal("yaml"))
+		Expect(opts.GetModeSpecs()).To(Equal([]output.ModeSpec{{Mode: "yaml"}, {Mode: "custom", Parameter: "a,b"}}))
+		Expect(flagutils.FlagArgs(fs)).To(Equal([]string{"--mode=yaml", "--mode=custom=a,b"}))
+	})
+
+	It("offers the modes as open choices", func() {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		opts.AddFlags(fs)
+		f := fs.Lookup("mode")
+		Expect(flagutils.GetFlagChoices(f)).To(Equal(opts.GetModes()))
+		Expect(flagutils.HasOpenFlagChoices(f)).To(BeTrue())
+	})
+
+	It("reports failing outputs", func() {
+		path := filepath.Join(dir, "missing", "out.json")
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), flagutilstest.RunnerFunc(process), "-o", "json@"+path, "-o", "yaml")
+		Expect(r.RunError).To(MatchError(ContainSubstring("output json@" + path + ": cannot create output file:")))
+		Expect(r.Stdout).To(Equal("---\n1\n---\n2\n"))
+	})
+
+	It("rejects duplicate files", func() {
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), nil, "-o", "json@out", "-o", "yaml@out")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring(`output file "out" used for multiple modes`)))
+	})
+
+	It("rejects invalid modes", func() {
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), nil, "-o", "json", "-o", "xml")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring("invalid output mode: xml")))
+	})
+
+	It("accepts interactively corrected mode specs", func() {
+		var prompts bytes.Buffer
+		path := filepath.Join(dir, "out.json")
+		ctx := flagutils.WithPrompter(context.Background(), prompt.New(strings.NewReader("json@"+path+"\n"), &prompts))
+		r := flagutilstest.Run(ctx, flagutils.NewOptionSet(opts), flagutilstest.RunnerFunc(process), "-o", "xml")
+		Expect(r.Error()).To(Succeed())
+		Expect(prompts.String()).To(HavePrefix("invalid output mode: xml"))
+		Expect(os.ReadFile(path)).To(Equal([]byte(`{"items":[1,2]}`)))
+	})
+
+	Context("parameterized modes", func() {
+		BeforeEach(func() {
+			opts = output.New(output.NewOutputsFactory[int]().AddManifestOutputs().Add("prefix", &prefixFactory{}))
+		})
+
+		It("describes the parameter", func() {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			opts.AddFlags(fs)
+			Expect(fs.Lookup("mode").Usage).To(Equal("output mode (JSON, YAML, json, prefix=<prefix>, yaml), modes without parameter optionally followed by @<file>"))
+		})
+
+		It("parses mode specs", func() {
+			Expect(output.ParseModeSpec("prefix=a=b")).To(Equal(output.ModeSpec{Mode: "prefix", Parameter: "a=b"}))
+			Expect(output.ParseModeSpec("prefix:a@b")).To(Equal(output.ModeSpec{Mode: "prefix", Parameter: "a@b"}))
+			Expect(output.ParseModeSpec("json@a=b")).To(Equal(output.ModeSpec{Mode: "json", File: "a=b"}))
+			Expect(output.ParseModeSpec("json=a")).To(Equal(output.ModeSpec{Mode: "json", Parameter: "a"}))
+		})
+
+		It("passes the parameter", func() {
+			r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), flagutilstest.RunnerFunc(process), "-o", "prefix:> ", "-o", "prefix=- ")
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal("> 1\n> 2\n- 1\n- 2\n"))
+			Expect(opts.GetFieldNames("")).To(BeEmpty())
+		})
+
+		It("validates the parameter", func() {
+			r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), nil, "-o", "prefix")
+			Expect(r.ValidationError).To(MatchError(ContainSubstring("invalid parameter for output mode prefix: prefix required")))
+			r = flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), nil, "-o", "json:x")
+			Expect(r.ValidationError).To(MatchError(ContainSubstring("output mode json does not accept a parameter")))
+		})
+
+		It("rejects parameters for other factories", func() {
+			opts = output.New[int](&plainOutputs{output.NewOutputsFactory[int]().AddManifestOutputs()})
+			r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), flagutilstest.RunnerFunc(process), "-o", "yaml")
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal("---\n1\n---\n2\n"))
+			r = flagutilstest.Run(context.Background(), flagutils.NewOptionSet(opts), nil, "-o", "yaml:x")
+			Expect(r.ValidationError).To(MatchError(ContainSubstring("output mode yaml does not accept a parameter")))
+		})
+	})
+})
@@ -12,8 +12,14 @@ import (
 
 const FIELD_MODE_OUTPUT = "<output>"
 
+var (
+	_ PresetOutputsFactory[int]        = (*outputsFactory[int])(nil)
+	_ ParameterizedOutputsFactory[int] = (*outputsFactory[int])(nil)
+)
+
 type outputsFactory[I any] struct {
-	modes map[string]OutputFactory[I]
+	modes   map[string]OutputFactory[I]
+	presets []flagutils.Preset
 }
 
 func NewOutputsFactory[I any](alt ...map[string]OutputFactory[I]) OutputsFactory[I] {
@@ -36,21 +42,108 @@ func (f *outputsFactory[I]) Add(mode string, out OutputFactory[I]) OutputsFactor
 }
 
 func (f *outputsFactory[I]) AddManifestOutputs() OutputsFactory[I] {
-	return manifest.AddManifestOutputs(f)
+	return manifest.AddManifestOutputs[I](f)
+}
+
+// AddPreset adds a built-in option preset offered by output Options
+// using this factory (see package preset).
+func (f *outputsFactory[I]) AddPreset(name, desc string, args ...string) OutputsFactory[I] {
+	f.presets = append(f.presets, flagutils.NewPreset(name, desc, args...))
+	return f
+}
+
+func (f *outputsFactory[I]) GetPresets() []flagutils.Preset {
+	return f.presets
+}
+
+// AddManifestOutputs adds the manifest output modes to an OutputsFactory
+// declaring the aggregated fields of the summary requested by the
+// summary option (see manifest.OutputFactory.WithSummary).
+func AddManifestOutputs[I any](f OutputsFactory[I], summary ...SummaryField) OutputsFactory[I] {
+	return manifest.AddManifestOutputs(f, summary...)
+}
+
+// AddPreset adds a built-in option preset to an OutputsFactory
+// implementing PresetOutputsFactory. Other factories don't offer
+// presets and are returned unchanged.
+func AddPreset[I any](f OutputsFactory[I], name, desc string, args ...string) OutputsFactory[I] {
+	if p, ok := f.(PresetOutputsFactory[I]); ok {
+		return p.AddPreset(name, desc, args...)
+	}
+	return f
+}
+
+func (f *outputsFactory[I]) GetParameterHelp(mode string) (string, bool) {
+	if p, ok := f.modes[mode].(ParameterizedOutputFactory[I]); ok {
+		return p.GetParameterHelp(), true
+	}
+	return "", false
 }
 
 func (f *outputsFactory[I]) GetFieldNames(mode, stage string) []string {
+	return f.GetParameterFieldNames(mode, "", stage)
+}
+
+func (f *outputsFactory[I]) GetParameterFieldNames(mode, param, stage string) []string {
 	of := f.modes[mode]
 	if of == nil {
 		return nil
 	}
+	if p, ok := of.(ParameterizedOutputFactory[I]); ok {
+		return p.GetParameterFieldNames(param, stage)
+	}
 	return of.GetFieldNames(stage)
 }
 
 func (f *outputsFactory[I]) CreateOutput(ctx context.Context, mode string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (Output[I], error) {
+	return f.CreateParameterizedOutput(ctx, mode, "", opts, v)
+}
+
+func (f *outputsFactory[I]) CreateParameterizedOutput(ctx context.Context, mode, param string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (Output[I], error) {
 	of := f.modes[mode]
 	if of == nil {
 		return nil, fmt.Errorf("invalid output mode: %s", mode)
 	}
-	return of.Create(ctx, opts, v)
+	p, ok := of.(ParameterizedOutputFactory[I])
+	if !ok {
+		if param != "" {
+			return nil, fmt.Errorf("output mode %s does not accept a parameter", mode)
+		}
+		return of.Create(ctx, opts, v)
+	}
+	if err := p.ValidateParameter(param); err != nil {
+		return nil, fmt.Errorf("invalid parameter for output mode %s: %w", mode, err)
+	}
+	return p.CreateWithParameter(ctx, param, opts, v)
+}
+
+// GetParameterHelp provides the parameter help for a parameterized
+// output mode of an OutputsFactory implementing ParameterizedOutputsFactory.
+func GetParameterHelp[I any](f OutputsFactory[I], mode string) (string, bool) {
+	if p, ok := f.(ParameterizedOutputsFactory[I]); ok {
+		return p.GetParameterHelp(mode)
+	}
+	return "", false
+}
+
+// GetFieldNames provides the field names of an output mode
+// for the given parameter.
+func GetFieldNames[I any](f OutputsFactory[I], mode, param, stage string) []string {
+	if p, ok := f.(ParameterizedOutputsFactory[I]); ok {
+		return p.GetParameterFieldNames(mode, param, stage)
+	}
+	return f.GetFieldNames(mode, stage)
+}
+
+// CreateOutput creates the output for an output mode and the given
+// parameter. A parameter is rejected, if the OutputsFactory does not
+// implement ParameterizedOutputsFactory.
+func CreateOutput[I any](ctx context.Context, f OutputsFactory[I], mode, param string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (Output[I], error) {
+	if p, ok := f.(ParameterizedOutputsFactory[I]); ok {
+		return p.CreateParameterizedOutput(ctx, mode, param, opts, v)
+	}
+	if param != "" {
+		return nil, fmt.Errorf("output mode %s does not accept a parameter", mode)
+	}
+	return f.CreateOutput(ctx, mode, opts, v)
 }
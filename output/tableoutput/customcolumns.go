@@ -0,0 +1,216 @@
+package tableoutput
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/manifest"
+)
+
+// MODE_CUSTOM_COLUMNS is the mode name used by AddCustomColumnsOutput.
+const MODE_CUSTOM_COLUMNS = "custom-columns"
+
+// NONE is the field value shown for a path not found in an element.
+const NONE = "<none>"
+
+// AddCustomColumnsOutput adds the custom columns output mode
+// to an OutputsFactory.
+func AddCustomColumnsOutput[I any](f output.OutputsFactory[I]) output.OutputsFactory[I] {
+	return f.Add(MODE_CUSTOM_COLUMNS, NewCustomColumnsFactory[I]())
+}
+
+// CustomColumnsFactory is a parameterized output mode showing
+// a table with columns described by the parameter
+// (<header>:<path>,...). A path (like .metadata.name or .items[0])
+// selects a value from the manifest representation of an element
+// (see manifest.Manifest) or the JSON representation of the element itself.
+// The column names are used as field names for the sort option.
+type CustomColumnsFactory[I any] struct{}
+
+var _ output.ParameterizedOutputFactory[int] = (*CustomColumnsFactory[int])(nil)
+
+func NewCustomColumnsFactory[I any]() *CustomColumnsFactory[I] {
+	return &CustomColumnsFactory[I]{}
+}
+
+func (f *CustomColumnsFactory[I]) GetFieldNames(stage string) []string {
+	return nil
+}
+
+func (f *CustomColumnsFactory[I]) Create(ctx context.Context, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (output.Output[I], error) {
+	return f.CreateWithParameter(ctx, "", opts, v)
+}
+
+func (f *CustomColumnsFactory[I]) GetParameterHelp() string {
+	return "<header>:<path>,..."
+}
+
+func (f *CustomColumnsFactory[I]) ValidateParameter(param string) error {
+	_, err := ParseCustomColumns(param)
+	return err
+}
+
+func (f *CustomColumnsFactory[I]) GetParameterFieldNames(param, stage string) []string {
+	cols, err := ParseCustomColumns(param)
+	if err != nil {
+		return nil
+	}
+	return NewOutputFactory[I, output.Fields](nil, cols.Headers()...).GetFieldNames(stage)
+}
+
+func (f *CustomColumnsFactory[I]) CreateWithParameter(ctx context.Context, param string, opts flagutils.OptionSetProvider, v flagutils.ValidationSet) (output.Output[I], error) {
+	cols, err := ParseCustomColumns(param)
+	if err != nil {
+		return nil, err
+	}
+	mapper := func(e I) output.Fields { return cols.Mapper(e) }
+	return NewOutputFactory[I, output.Fields](mapper, cols.Headers()...).Create(ctx, opts, v)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// CustomColumn describes a table column by a header and a path.
+type CustomColumn struct {
+	Header string
+	Path   Path
+}
+
+type CustomColumns []CustomColumn
+
+// ParseCustomColumns parses a column specification of the
+// form <header>:<path>,...
+func ParseCustomColumns(spec string) (CustomColumns, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("column specification required")
+	}
+	var cols CustomColumns
+	for _, c := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(c, ":")
+		if !ok || header == "" {
+			return nil, fmt.Errorf("invalid column %q: <header>:<path> expected", c)
+		}
+		p, err := ParsePath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column %q: %w", c, err)
+		}
+		cols = append(cols, CustomColumn{header, p})
+	}
+	return cols, nil
+}
+
+func (c CustomColumns) Headers() []string {
+	var headers []string
+	for _, e := range c {
+		headers = append(headers, e.Header)
+	}
+	return headers
+}
+
+// Mapper maps an element to the field values of the columns.
+func (c CustomColumns) Mapper(e any) output.Fields {
+	data, err := manifest.Data(e)
+	var fields output.Fields
+	for _, col := range c {
+		if err != nil {
+			fields = append(fields, err.Error())
+		} else {
+			fields = append(fields, FormatValue(col.Path.Get(data)))
+		}
+	}
+	return fields
+}
+
+// FormatValue formats a value of a generic JSON representation
+// as field value.
+func FormatValue(v any) string {
+	switch e := v.(type) {
+	case nil:
+		return NONE
+	case string:
+		return e
+	case json.Number, bool:
+		return fmt.Sprintf("%v", e)
+	default:
+		d, err := json.Marshal(e)
+		if err != nil {
+			return err.Error()
+		}
+		return string(d)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Path is a path into a generic JSON representation of
+// an element. A step is either a field name or a list index.
+type Path []any
+
+// ParsePath parses a path of the form .<field>[<index>]...
+// Optionally, it may be enclosed in curly braces ({.<field>}).
+// The path . describes the complete value.
+func ParsePath(s string) (Path, error) {
+	orig := s
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	if !strings.HasPrefix(s, ".") {
+		return nil, fmt.Errorf("path %q must start with '.'", orig)
+	}
+	if s == "." {
+		return Path{}, nil
+	}
+
+	var path Path
+	for _, step := range strings.Split(s[1:], ".") {
+		name, index, _ := strings.Cut(step, "[")
+		if name == "" && index == "" {
+			return nil, fmt.Errorf("empty step in path %q", orig)
+		}
+		if name != "" {
+			path = append(path, name)
+		}
+		for index != "" {
+			i, rest, ok := strings.Cut(index, "]")
+			if !ok {
+				return nil, fmt.Errorf("unterminated index in path %q", orig)
+			}
+			n, err := strconv.Atoi(i)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", i, orig)
+			}
+			path = append(path, n)
+			if rest != "" && !strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("invalid step %q in path %q", step, orig)
+			}
+			index = strings.TrimPrefix(rest, "[")
+		}
+	}
+	return path, nil
+}
+
+// Get provides the value for the path. It is nil if the
+// path does not exist.
+func (p Path) Get(data any) any {
+	for _, step := range p {
+		switch s := step.(type) {
+		case string:
+			m, ok := data.(map[string]any)
+			if !ok {
+				return nil
+			}
+			data = m[s]
+		case int:
+			l, ok := data.([]any)
+			if !ok || s >= len(l) {
+				return nil
+			}
+			data = l[s]
+		}
+	}
+	return data
+}
@@ -0,0 +1,103 @@
+package tableoutput_test
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/sort"
+)
+
+type Element struct {
+	Name   string            `json:"name"`
+	Size   int               `json:"size"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Tags   []string          `json:"tags,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+func (e *Element) GetError() error {
+	if e.Error == "" {
+		return nil
+	}
+	return fmt.Errorf("%s", e.Error)
+}
+
+type source []*Element
+
+func (s source) Elements(output.ElementSpecs) (iter.Seq[*Element], error) {
+	return slices.Values(s), nil
+}
+
+var _ = Describe("custom columns", func() {
+	var set flagutils.OptionSet
+
+	elements := source{
+		{Name: "b", Size: 1000000, Tags: []string{"x", "y"}},
+		{Name: "a", Size: 5, Labels: map[string]string{"env": "dev"}},
+	}
+
+	BeforeEach(func() {
+		outputs := tableoutput.AddCustomColumnsOutput(output.NewOutputsFactory[*Element]())
+		set = flagutils.NewOptionSet(sort.New(), tableoutput.New(), output.New(outputs))
+	})
+
+	run := func(args ...string) *flagutilstest.Result {
+		return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			_, err := output.From[*Element](opts).GetOutput().Process(ctx, nil, elements)
+			return err
+		}), args...)
+	}
+
+	It("shows the columns", func() {
+		r := run("-o", "custom-columns=NAME:.name,-SIZE:.size,ENV:.labels.env,TAG:.tags[1]")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`NAME    SIZE ENV    TAG
+b    1000000 <none> y
+a          5 dev    <none>
+`))
+	})
+
+	It("sorts by custom columns", func() {
+		r := run("-o", "custom-columns:NAME:.name,TAGS:{.tags}", "-s", "name")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`NAME TAGS
+a    <none>
+b    ["x","y"]
+`))
+	})
+
+	It("rejects invalid sort fields", func() {
+		r := run("-o", "custom-columns=NAME:.name", "-s", "size")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring("invalid sort fields: [size]")))
+	})
+
+	It("rejects invalid columns", func() {
+		r := run("-o", "custom-columns")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring("invalid parameter for output mode custom-columns: column specification required")))
+		r = run("-o", "custom-columns=NAME")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring(`invalid column "NAME": <header>:<path> expected`)))
+		r = run("-o", "custom-columns=NAME:name")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring(`invalid column "NAME:name": path "name" must start with '.'`)))
+	})
+
+	It("parses paths", func() {
+		Expect(tableoutput.ParsePath(".a.b[1][2].c")).To(Equal(tableoutput.Path{"a", "b", 1, 2, "c"}))
+		Expect(tableoutput.ParsePath("{.a}")).To(Equal(tableoutput.Path{"a"}))
+		Expect(tableoutput.ParsePath(".")).To(Equal(tableoutput.Path{}))
+		_, err := tableoutput.ParsePath(".a[x]")
+		Expect(err).To(MatchError(`invalid index "x" in path ".a[x]"`))
+		_, err = tableoutput.ParsePath(".a..b")
+		Expect(err).To(MatchError(`empty step in path ".a..b"`))
+		Expect(Must(tableoutput.ParsePath(".a[1]")).Get(map[string]any{"a": []any{1, 2}})).To(Equal(2))
+	})
+})
@@ -0,0 +1,99 @@
+package tableoutput_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/errorpolicy"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+)
+
+var _ = Describe("element errors", func() {
+	var set flagutils.OptionSet
+
+	elements := source{
+		{Name: "b", Size: 1000, Error: "b failed"},
+		{Name: "a", Size: 5},
+	}
+
+	mapper := func(e *Element) output.Fields {
+		return output.Fields{e.Name, fmt.Sprintf("%d", e.Size)}
+	}
+
+	BeforeEach(func() {
+		outputs := output.NewOutputsFactory[*Element]().
+			Add("", tableoutput.NewOutputFactory[*Element](mapper, "NAME", "-SIZE")).
+			AddManifestOutputs()
+		set = flagutils.NewOptionSet(errorpolicy.New(), tableoutput.New(), output.New(outputs))
+	})
+
+	run := func(args ...string) *flagutilstest.Result {
+		return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			_, err := output.From[*Element](opts).GetOutput().Process(ctx, nil, elements)
+			return err
+		}), args...)
+	}
+
+	It("reports failed elements", func() {
+		r := run()
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME SIZE\nb    1000\na       5\n"))
+		Expect(r.Stderr).To(Equal("Error: b failed\n"))
+	})
+
+	It("fails with the default status", func() {
+		r := run("--fail-on-error")
+		Expect(r.RunError).To(MatchError("1 element failed"))
+		Expect(flagutils.ExitCode(r.RunError)).To(Equal(flagutils.EXIT_FAILURE))
+		Expect(r.Stderr).To(Equal("Error: b failed\n"))
+	})
+
+	It("fails with a configured status", func() {
+		r := run("--fail-on-error=5")
+		Expect(flagutils.ExitCode(r.RunError)).To(Equal(5))
+		Expect(flagutils.ExitCode(&flagutils.LifecycleError{Phase: flagutils.PHASE_RUN, Err: r.RunError})).To(Equal(5))
+	})
+
+	It("reports failed elements once for multiple modes", func() {
+		r := run("-o", "json", "-o", "yaml", "--fail-on-error")
+		Expect(r.RunError).To(MatchError("1 element failed"))
+		Expect(r.Stderr).To(Equal("Error: b failed\n"))
+	})
+
+	It("rejects invalid status", func() {
+		r := run("--fail-on-error=-1")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring("invalid exit status -1")))
+	})
+
+	It("adds an errors section to the item list", func() {
+		r := run("-o", "json")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`{"items":[{"name":"b","size":1000,"error":"b failed"},{"name":"a","size":5}],"errors":[{"index":0,"error":"b failed"}]}`))
+		Expect(r.Stderr).To(Equal("Error: b failed\n"))
+	})
+
+	It("adds an errors section to the YAML item list", func() {
+		r := run("-o", "YAML")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(HaveSuffix(`errors:
+    - index: 0
+      error: b failed
+`))
+	})
+
+	It("adds an errors document", func() {
+		r := run("-o", "yaml")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(HaveSuffix(`---
+errors:
+    - index: 0
+      error: b failed
+`))
+	})
+})
@@ -4,11 +4,13 @@ import (
 	"context"
 	"github.com/mandelsoft/flagutils"
 	"github.com/mandelsoft/flagutils/closure"
+	"github.com/mandelsoft/flagutils/humanize"
 	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/progress"
 	"github.com/mandelsoft/flagutils/sort"
+	"github.com/mandelsoft/flagutils/summary"
 	"github.com/mandelsoft/streaming/chain"
 	"slices"
-	"strings"
 )
 
 type FieldProvider = output.FieldProvider
@@ -30,15 +32,70 @@ func NewExtendedOutputFactory[I any, F FieldProvider](mapper chain.Mapper[I, F],
 	return &OutputFactory[I, F]{mapper: mapper, chain: chain, headers: slices.Clone(headers)}
 }
 
+// OutputFactory is an output mode showing elements as table.
+// Headers may declare a visibility level for their column (see Wide and
+// Debug). Hidden columns are still provided by the mapper and can be
+// used for sorting or selected with the --columns option.
 type OutputFactory[I any, F FieldProvider] struct {
 	provider output.MappingProvider[I, F]
 	mapper   chain.Mapper[I, F]
 	chain    chain.Chain[F, FieldProvider]
 	headers  []string
+	level    string
+	fixed    int
+	pre      int
+	renderer Renderer
+	summary  []output.SummaryField
 }
 
 var _ output.OutputFactory[int] = (*OutputFactory[int, FieldProvider])(nil)
 
+// WithLevel provides a copy of the factory showing the columns
+// up to the given visibility level. This way, the same mapper can be
+// used for multiple modes, for example, "" and "wide".
+func (o *OutputFactory[I, F]) WithLevel(level string) *OutputFactory[I, F] {
+	n := *o
+	n.level = level
+	return &n
+}
+
+// WithFixedColumns provides a copy of the factory always showing
+// the first n columns.
+func (o *OutputFactory[I, F]) WithFixedColumns(n int) *OutputFactory[I, F] {
+	c := *o
+	c.fixed = n
+	return &c
+}
+
+// WithPreformattedColumns provides a copy of the factory rendering
+// the first n columns as preformatted text (see Renderer).
+func (o *OutputFactory[I, F]) WithPreformattedColumns(n int) *OutputFactory[I, F] {
+	c := *o
+	c.pre = n
+	return &c
+}
+
+// WithRenderer provides a copy of the factory using the given
+// Renderer instead of FormatTable (see AddRenderedOutputs).
+func (o *OutputFactory[I, F]) WithRenderer(r Renderer) *OutputFactory[I, F] {
+	c := *o
+	c.renderer = r
+	return &c
+}
+
+// WithSummary provides a copy of the factory declaring the
+// column aggregations shown in the summary rows requested
+// by the summary option.
+func (o *OutputFactory[I, F]) WithSummary(fields ...output.SummaryField) *OutputFactory[I, F] {
+	c := *o
+	c.summary = slices.Clone(fields)
+	return &c
+}
+
+func (o *OutputFactory[I, F]) GetLevel() string {
+	return o.level
+}
+
 func (o *OutputFactory[I, F]) GetMapper() chain.Mapper[I, F] {
 	return o.mapper
 }
@@ -51,16 +108,12 @@ func (o *OutputFactory[I, F]) GetHeaders() []string {
 	return slices.Clone(o.headers)
 }
 
+// GetFieldNames provides the field names of all columns,
+// regardless of their visibility level.
 func (o *OutputFactory[I, F]) GetFieldNames(stage string) []string {
-	fields := slices.Clone(o.headers)
-	for i := range fields {
-		if strings.HasPrefix(fields[i], "-") {
-			fields[i] = fields[i][1:]
-		}
-	}
-	return fields
-
+	return FieldNames(o.headers)
 }
+
 func (o *OutputFactory[I, F]) getMapper(opts flagutils.OptionSetProvider) (chain.Mapper[I, F], error) {
 	mapper := o.mapper
 	if mapper == nil {
@@ -78,10 +131,29 @@ func (o *OutputFactory[I, F]) Create(ctx context.Context, opts flagutils.OptionS
 	if err != nil {
 		return nil, err
 	}
+	if err := checkColumns(From(opts), o.headers); err != nil {
+		return nil, err
+	}
 
-	// compose chain: exploder -> mapper -> sort -> custom chain
-	c := closure.AddExplodeChain(opts, chain.New[I]())
+	// compose chain: exploder -> error check -> progress -> mapper -> sort -> custom chain
+	errs, report := output.GetErrorReporter(opts)
+	rep := progress.GetReporter(opts)
+	c := output.AddErrorChain(errs, closure.AddExplodeChain(opts, chain.New[I]()))
+	c = progress.AddCountChain(rep, progress.STAGE_PRODUCED, c)
 	mapped := sort.AddSortChain[I, F](opts, chain.AddMap[F](c, mapper))
-	co := chain.AddChain(mapped, o.chain)
-	return output.NewOutput[I, FieldProvider](co, &Factory[FieldProvider]{slices.Clone(o.headers), From(opts)}), nil
+	co := progress.AddCountChain(rep, progress.STAGE_PROCESSED, chain.AddChain(mapped, o.chain))
+	return output.NewOutput[I, FieldProvider](co, &Factory[FieldProvider]{
+		Headers:      slices.Clone(o.headers),
+		Options:      From(opts),
+		Level:        o.level,
+		Fixed:        o.fixed,
+		Preformatted: o.pre,
+		Renderer:     o.renderer,
+		Summary:      o.summary,
+		ShowSummary:  summary.Requested(opts),
+		Errors:       errs,
+		ReportErrors: report,
+		Progress:     rep,
+		Raw:          humanize.IsRaw(opts),
+	}), nil
 }
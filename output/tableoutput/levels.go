@@ -0,0 +1,108 @@
+package tableoutput
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Column visibility levels. A header may declare the level
+// of its column by the suffix @<level>. Columns of a level are
+// shown if the output factory uses this or a higher level
+// (see OutputFactory.WithLevel).
+const (
+	LEVEL_DEFAULT = ""
+	LEVEL_WIDE    = "wide"
+	LEVEL_DEBUG   = "debug"
+)
+
+var levels = []string{LEVEL_DEFAULT, LEVEL_WIDE, LEVEL_DEBUG}
+
+// Wide declares a header for the level LEVEL_WIDE.
+func Wide(header string) string {
+	return header + "@" + LEVEL_WIDE
+}
+
+// Debug declares a header for the level LEVEL_DEBUG.
+func Debug(header string) string {
+	return header + "@" + LEVEL_DEBUG
+}
+
+// ParseHeader splits a header into the column title (including
+// an optional alignment prefix -) and the visibility level.
+func ParseHeader(h string) (string, string) {
+	if i := strings.LastIndex(h, "@"); i >= 0 && slices.Contains(levels, h[i+1:]) {
+		return h[:i], h[i+1:]
+	}
+	return h, LEVEL_DEFAULT
+}
+
+// FieldName provides the field name for a header.
+func FieldName(h string) string {
+	t, _ := ParseHeader(h)
+	return strings.TrimPrefix(t, "-")
+}
+
+// FieldNames provides the field names for a list of headers.
+func FieldNames(headers []string) []string {
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		names[i] = FieldName(h)
+	}
+	return names
+}
+
+// IsVisible reports whether a column of the given level is shown
+// for the given output level.
+func IsVisible(level, output string) bool {
+	return slices.Index(levels, level) <= slices.Index(levels, output)
+}
+
+// checkColumns checks the columns selected by the table
+// output options against the available headers.
+func checkColumns(opts *Options, headers []string) error {
+	if opts == nil {
+		return nil
+	}
+	var wrong []string
+	names := FieldNames(headers)
+	for _, c := range opts.UseColumns() {
+		if indexFold(names, c) < 0 {
+			wrong = append(wrong, c)
+		}
+	}
+	if len(wrong) > 0 {
+		return fmt.Errorf("invalid columns: %v", wrong)
+	}
+	return nil
+}
+
+// selectColumns determines the indices of the shown columns.
+// The first fixed columns are always shown.
+func selectColumns(opts *Options, headers []string, level string, fixed int) []int {
+	var idx []int
+	for i := 0; i < fixed && i < len(headers); i++ {
+		idx = append(idx, i)
+	}
+
+	if opts != nil && len(opts.UseColumns()) > 0 {
+		names := FieldNames(headers)
+		for _, c := range opts.UseColumns() {
+			if i := indexFold(names, c); i >= fixed {
+				idx = append(idx, i)
+			}
+		}
+		return idx
+	}
+	for i := fixed; i < len(headers); i++ {
+		_, l := ParseHeader(headers[i])
+		if (opts != nil && opts.UseAllColumns()) || IsVisible(l, level) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func indexFold(list []string, s string) int {
+	return slices.IndexFunc(list, func(e string) bool { return strings.EqualFold(e, s) })
+}
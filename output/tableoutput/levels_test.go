@@ -0,0 +1,125 @@
+package tableoutput_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/humanize"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/sort"
+	"github.com/mandelsoft/flagutils/summary"
+)
+
+var _ = Describe("column levels", func() {
+	var set flagutils.OptionSet
+
+	elements := source{
+		{Name: "b", Size: 1000, Tags: []string{"x", "y"}},
+		{Name: "a", Size: 5},
+	}
+
+	mapper := func(e *Element) output.Fields {
+		return output.Fields{e.Name, fmt.Sprintf("%d", e.Size), strings.Join(e.Tags, ",")}
+	}
+
+	BeforeEach(func() {
+		f := tableoutput.NewOutputFactory[*Element](mapper, "NAME", tableoutput.Wide("-SIZE"), tableoutput.Debug("TAGS"))
+		outputs := output.NewOutputsFactory[*Element]().
+			Add("", f).
+			Add("wide", f.WithLevel(tableoutput.LEVEL_WIDE)).
+			Add("debug", f.WithLevel(tableoutput.LEVEL_DEBUG))
+		set = flagutils.NewOptionSet(sort.New(), tableoutput.New(), output.New(outputs))
+	})
+
+	run := func(args ...string) *flagutilstest.Result {
+		return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			_, err := output.From[*Element](opts).GetOutput().Process(ctx, nil, elements)
+			return err
+		}), args...)
+	}
+
+	It("shows default columns", func() {
+		r := run()
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME\nb\na\n"))
+	})
+
+	It("shows wide columns", func() {
+		r := run("-o", "wide")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME SIZE\nb    1000\na       5\n"))
+	})
+
+	It("shows debug columns", func() {
+		r := run("-o", "debug")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME SIZE TAGS\nb    1000 x,y\na       5 \n"))
+	})
+
+	It("sorts by hidden columns", func() {
+		r := run("-s", "tags")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME\na\nb\n"))
+	})
+
+	It("shows selected columns", func() {
+		r := run("--columns", "tags,name")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("TAGS NAME\nx,y  b\n     a\n"))
+	})
+
+	It("rejects unknown columns", func() {
+		r := run("--columns", "name,mode")
+		Expect(r.ValidationError).To(MatchError(ContainSubstring("invalid columns: [mode]")))
+	})
+
+	Context("column optimization", func() {
+		elements := source{
+			{Name: "b", Size: 5},
+			{Name: "a", Size: 5},
+		}
+
+		BeforeEach(func() {
+			mapper := func(e *Element) output.Fields {
+				return output.Fields{fmt.Sprintf("%d", e.Size), e.Name}
+			}
+			f := tableoutput.NewOutputFactory[*Element](mapper, "-SIZE", "NAME")
+			outputs := output.NewOutputsFactory[*Element]().Add("", f.WithSummary(output.Total("SIZE")))
+			set = flagutils.NewOptionSet(humanize.New(), summary.New(), tableoutput.New().WithOptimizedColumns(1), output.New(outputs))
+		})
+
+		run := func(args ...string) *flagutilstest.Result {
+			return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+				_, err := output.From[*Element](opts).GetOutput().Process(ctx, nil, elements)
+				return err
+			}), args...)
+		}
+
+		It("optimizes selected columns", func() {
+			r := run("--columns", "size,name")
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal("NAME\nb\na\n"))
+		})
+
+		It("keeps the raw values for the summary", func() {
+			r := run("--raw", "--summary", "--columns", "name")
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal("NAME\nb\na\nTOTAL (2)\n"))
+		})
+	})
+
+	It("parses headers", func() {
+		title, level := tableoutput.ParseHeader("-SIZE@wide")
+		Expect(title).To(Equal("-SIZE"))
+		Expect(level).To(Equal(tableoutput.LEVEL_WIDE))
+		Expect(tableoutput.FieldName("-SIZE@wide")).To(Equal("SIZE"))
+		Expect(tableoutput.FieldName("A@B")).To(Equal("A@B"))
+	})
+})
@@ -4,17 +4,41 @@ import (
 	"context"
 	"fmt"
 	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/progress"
 	"github.com/mandelsoft/flagutils/utils/out"
 	"github.com/mandelsoft/goutils/iterutils"
 	"github.com/mandelsoft/goutils/sliceutils"
 	"github.com/mandelsoft/streaming"
 	"iter"
+	"slices"
 	"strings"
 )
 
 type Factory[F FieldProvider] struct {
 	Headers []string
 	Options *Options
+	// Level is the visibility level of the shown columns.
+	Level string
+	// Fixed is the number of leading columns always shown.
+	Fixed int
+	// Preformatted is the number of leading columns with preformatted text.
+	Preformatted int
+	// Renderer renders the table. The default is RenderText.
+	Renderer Renderer
+	// Summary declares the aggregated columns.
+	Summary []output.SummaryField
+	// ShowSummary enables the summary rows.
+	ShowSummary bool
+	// Errors records the errors of the elements.
+	Errors *output.ErrorReporter
+	// ReportErrors reports the recorded errors after the processing.
+	// It is not set for the reporter shared by the outputs of a run, which
+	// is reported by the output of the output options (see errorpolicy.GetReporter).
+	ReportErrors bool
+	// Progress reports the progress while processing the elements.
+	Progress *progress.Reporter
+	// Raw shows the raw field values (see output.RawFieldProvider).
+	Raw bool
 }
 
 var _ streaming.ProcessorFactory[output.ElementSpecs, int, FieldProvider] = (*Factory[FieldProvider])(nil)
@@ -26,6 +50,7 @@ func (o *Factory[F]) Processor(output.ElementSpecs) (streaming.Processor[int, F]
 type Processor[F FieldProvider] struct {
 	output *Factory[F]
 	data   [][]string
+	raw    [][]string
 }
 
 var (
@@ -40,22 +65,133 @@ func newProcessor[F FieldProvider](o *Factory[F]) *Processor[F] {
 }
 
 func (p *Processor[F]) Process(ctx context.Context, i iter.Seq[F]) (int, error) {
-	p.data = sliceutils.Transform(iterutils.Get(i), func(e F) []string { return e.GetFields() })
+	p.output.Progress.Start(ctx)
+	defer p.output.Progress.Stop()
+	elems := iterutils.Get(i)
+	// the progress line must be cleared before the output is written.
+	p.output.Progress.Stop()
+	p.raw = sliceutils.Transform(elems, func(e F) []string { return output.GetRawFields(e) })
+	if p.output.Raw {
+		// the column selection replaces the rows, the raw values
+		// are still required for the summary.
+		p.data = slices.Clone(p.raw)
+	} else {
+		p.data = sliceutils.Transform(elems, func(e F) []string { return e.GetFields() })
+	}
 
 	if len(p.data) == 0 {
 		out.Print(ctx, "no elements found\n")
-		return 0, nil
+		return 0, p.reportErrors(ctx)
 	}
-	effheader := p.output.Headers
+	var sum *output.Summary
+	if p.output.ShowSummary {
+		sum = p.summarize()
+	}
+	effheader := p.selectColumns()
 	if p.output.Options.UseColumnOptimization() {
-		effheader = p.optimizeColumns()
+		effheader = p.optimizeColumns(effheader)
+	}
+	render := p.output.Renderer
+	if render == nil {
+		render = RenderText
+	}
+	rows := append([][]string{effheader}, p.data...)
+	if sum != nil {
+		rows = append(rows, p.footer(effheader, sum)...)
+	}
+	render(ctx, rows, min(p.output.Preformatted, len(effheader)))
+	return len(p.data), p.reportErrors(ctx)
+}
+
+func (p *Processor[F]) reportErrors(ctx context.Context) error {
+	if !p.output.ReportErrors {
+		return nil
+	}
+	return p.output.Errors.Report(ctx)
+}
+
+// summarize calculates the summary for all columns based on
+// the raw values, regardless of their visibility.
+func (p *Processor[F]) summarize() *output.Summary {
+	names := FieldNames(p.output.Headers)
+	s := output.NewSummarizer(p.output.Summary...)
+	for _, row := range p.raw {
+		s.Add(func(name string) any {
+			if i := indexFold(names, name); i >= 0 && i < len(row) {
+				return row[i]
+			}
+			return nil
+		})
+	}
+	return s.Summary()
+}
+
+// footer provides the summary rows for the shown columns.
+// The first shown column without aggregation is used for the
+// row labels. The first row shows the element count and the totals,
+// an optional second row the averages.
+func (p *Processor[F]) footer(headers []string, sum *output.Summary) [][]string {
+	names := FieldNames(headers)
+	label := slices.IndexFunc(names, func(n string) bool {
+		return !slices.ContainsFunc(p.output.Summary, func(f output.SummaryField) bool { return strings.EqualFold(f.Name, n) })
+	})
+
+	var rows [][]string
+	for _, a := range []output.Aggregation{output.TOTAL, output.AVERAGE} {
+		row := make([]string, len(headers))
+		found := false
+		for _, f := range p.output.Summary {
+			if f.Aggregation != a {
+				continue
+			}
+			found = true
+			if v, ok := sum.Get(f); ok {
+				if i := indexFold(names, f.Name); i >= 0 {
+					row[i] = output.FormatNumber(v)
+				}
+			}
+		}
+		switch {
+		case a == output.TOTAL:
+			if label >= 0 {
+				row[label] = fmt.Sprintf("TOTAL (%d)", sum.Count)
+			}
+		case !found:
+			continue
+		case label >= 0:
+			row[label] = "AVERAGE"
+		}
+		rows = append(rows, row)
 	}
-	FormatTable(ctx, "", append([][]string{effheader}, p.data...))
-	return len(p.data), nil
+	return rows
+}
+
+// selectColumns reduces the data to the shown columns
+// and provides the appropriate headers.
+func (p *Processor[F]) selectColumns() []string {
+	idx := selectColumns(p.output.Options, p.output.Headers, p.output.Level, p.output.Fixed)
+	headers := make([]string, len(idx))
+	for i, c := range idx {
+		headers[i], _ = ParseHeader(p.output.Headers[c])
+	}
+	// fields without header are kept, if no columns are selected explicitly
+	keep := len(p.output.Options.UseColumns()) == 0
+	for j, row := range p.data {
+		sel := make([]string, len(idx))
+		for i, c := range idx {
+			if c < len(row) {
+				sel[i] = row[c]
+			}
+		}
+		if keep && len(row) > len(p.output.Headers) {
+			sel = append(sel, row[len(p.output.Headers):]...)
+		}
+		p.data[j] = sel
+	}
+	return headers
 }
 
-func (p *Processor[F]) optimizeColumns() []string {
-	headers := p.output.Headers
+func (p *Processor[F]) optimizeColumns(headers []string) []string {
 	if len(p.data) < 2 {
 		return headers
 	}
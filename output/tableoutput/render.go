@@ -0,0 +1,144 @@
+package tableoutput
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// Output modes added by AddRenderedOutputs.
+const (
+	MODE_MARKDOWN = "markdown"
+	MODE_HTML     = "html"
+	MODE_ASCIIDOC = "asciidoc"
+)
+
+// Renderer renders a table given by a header row followed by
+// the data rows on the output context. Headers prefixed with -
+// describe right aligned columns. The first pre columns contain
+// preformatted text (like the graph of a tree output).
+type Renderer func(ctx context.Context, data [][]string, pre int)
+
+// AddRenderedOutputs adds the output modes MODE_MARKDOWN, MODE_HTML
+// and MODE_ASCIIDOC for a table output factory.
+func AddRenderedOutputs[I any, F FieldProvider](outputs output.OutputsFactory[I], f *OutputFactory[I, F]) output.OutputsFactory[I] {
+	return outputs.
+		Add(MODE_MARKDOWN, f.WithRenderer(RenderMarkdown)).
+		Add(MODE_HTML, f.WithRenderer(RenderHTML)).
+		Add(MODE_ASCIIDOC, f.WithRenderer(RenderAsciiDoc))
+}
+
+// RenderText is the default Renderer using FormatTable.
+func RenderText(ctx context.Context, data [][]string, pre int) {
+	FormatTable(ctx, "", data)
+}
+
+func title(h string) (string, bool) {
+	if strings.HasPrefix(h, "-") {
+		return h[1:], true
+	}
+	return h, false
+}
+
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// RenderMarkdown renders a GitHub-flavored Markdown table.
+// Preformatted cells are rendered as code spans keeping their spacing.
+func RenderMarkdown(ctx context.Context, data [][]string, pre int) {
+	escape := strings.NewReplacer("|", `\|`, "\n", "<br>")
+
+	headers := data[0]
+	var line, sep strings.Builder
+	for _, h := range headers {
+		t, right := title(h)
+		line.WriteString("| " + escape.Replace(t) + " ")
+		if right {
+			sep.WriteString("|---:")
+		} else {
+			sep.WriteString("|---")
+		}
+	}
+	out.Printf(ctx, "%s|\n%s|\n", line.String(), sep.String())
+
+	for _, row := range data[1:] {
+		line.Reset()
+		for i := range headers {
+			c := escape.Replace(cell(row, i))
+			if i < pre && c != "" {
+				c = "`" + strings.ReplaceAll(c, " ", "\u00a0") + "`"
+			}
+			line.WriteString("| " + c + " ")
+		}
+		out.Printf(ctx, "%s|\n", line.String())
+	}
+}
+
+// RenderHTML renders an HTML table. Preformatted cells are
+// enclosed in a pre element.
+func RenderHTML(ctx context.Context, data [][]string, pre int) {
+	headers := data[0]
+	align := make([]string, len(headers))
+
+	var line strings.Builder
+	for i, h := range headers {
+		t, right := title(h)
+		if right {
+			align[i] = ` align="right"`
+		}
+		line.WriteString("<th" + align[i] + ">" + html.EscapeString(t) + "</th>")
+	}
+	out.Printf(ctx, "<table>\n<thead>\n<tr>%s</tr>\n</thead>\n<tbody>\n", line.String())
+
+	for _, row := range data[1:] {
+		line.Reset()
+		for i := range headers {
+			c := html.EscapeString(cell(row, i))
+			if i < pre {
+				c = "<pre>" + c + "</pre>"
+			}
+			line.WriteString("<td" + align[i] + ">" + c + "</td>")
+		}
+		out.Printf(ctx, "<tr>%s</tr>\n", line.String())
+	}
+	out.Printf(ctx, "</tbody>\n</table>\n")
+}
+
+// RenderAsciiDoc renders an AsciiDoc table. Preformatted
+// columns use the literal style.
+func RenderAsciiDoc(ctx context.Context, data [][]string, pre int) {
+	escape := strings.NewReplacer("|", `\|`)
+
+	headers := data[0]
+	var cols []string
+	var line strings.Builder
+	for i, h := range headers {
+		t, right := title(h)
+		c := "<"
+		if right {
+			c = ">"
+		}
+		if i < pre {
+			c += "l"
+		}
+		cols = append(cols, c)
+		line.WriteString("|" + escape.Replace(t) + " ")
+	}
+	out.Printf(ctx, "[cols=\"%s\",options=\"header\"]\n|===\n%s\n", strings.Join(cols, ","), strings.TrimSuffix(line.String(), " "))
+
+	for _, row := range data[1:] {
+		line.Reset()
+		for i := range headers {
+			line.WriteString("|" + escape.Replace(cell(row, i)) + " ")
+		}
+		out.Printf(ctx, "%s\n", strings.TrimSuffix(line.String(), " "))
+	}
+	out.Printf(ctx, "|===\n")
+}
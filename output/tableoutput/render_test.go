@@ -0,0 +1,124 @@
+package tableoutput_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/prompt"
+	"github.com/mandelsoft/flagutils/sort"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+var _ = Describe("renderers", func() {
+	var set flagutils.OptionSet
+
+	elements := source{
+		{Name: "a|b", Size: 5},
+		{Name: "<c>", Size: 1000},
+	}
+
+	mapper := func(e *Element) output.Fields {
+		return output.Fields{e.Name, fmt.Sprintf("%d", e.Size)}
+	}
+
+	BeforeEach(func() {
+		f := tableoutput.NewOutputFactory[*Element](mapper, "NAME", "-SIZE")
+		outputs := tableoutput.AddRenderedOutputs(output.NewOutputsFactory[*Element]().Add("", f), f)
+		set = flagutils.NewOptionSet(sort.New(), tableoutput.New(), output.New(outputs))
+	})
+
+	run := func(args ...string) *flagutilstest.Result {
+		return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			_, err := output.From[*Element](opts).GetOutput().Process(ctx, nil, elements)
+			return err
+		}), args...)
+	}
+
+	It("renders markdown", func() {
+		r := run("-o", "markdown")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`| NAME | SIZE |
+|---|---:|
+| a\|b | 5 |
+| <c> | 1000 |
+`))
+	})
+
+	It("renders html", func() {
+		r := run("-o", "html")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`<table>
+<thead>
+<tr><th>NAME</th><th align="right">SIZE</th></tr>
+</thead>
+<tbody>
+<tr><td>a|b</td><td align="right">5</td></tr>
+<tr><td>&lt;c&gt;</td><td align="right">1000</td></tr>
+</tbody>
+</table>
+`))
+	})
+
+	It("renders asciidoc", func() {
+		r := run("-o", "asciidoc")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`[cols="<,>",options="header"]
+|===
+|NAME |SIZE
+|a\|b |5
+|<c> |1000
+|===
+`))
+	})
+
+	It("sorts by interactively corrected sort fields", func() {
+		var prompts bytes.Buffer
+		ctx := flagutils.WithPrompter(context.Background(), prompt.New(strings.NewReader("-size\n"), &prompts))
+		r := flagutilstest.Run(ctx, set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			_, err := output.From[*Element](opts).GetOutput().Process(ctx, nil, source{
+				{Name: "a", Size: 5},
+				{Name: "b", Size: 7},
+				{Name: "c", Size: 5},
+			})
+			return err
+		}), "-s", "-size,unknown")
+		Expect(r.Error()).To(Succeed())
+		Expect(prompts.String()).To(HavePrefix("invalid sort fields: [unknown]"))
+		Expect(r.Stdout).To(Equal(`NAME SIZE
+b       7
+a       5
+c       5
+`))
+	})
+
+	Context("preformatted columns", func() {
+		data := [][]string{{"", "NAME"}, {"└─ ", "a"}}
+
+		render := func(r tableoutput.Renderer) string {
+			var buf bytes.Buffer
+			r(out.With(context.Background(), out.New(&buf, nil)), data, 1)
+			return buf.String()
+		}
+
+		It("renders markdown code spans", func() {
+			Expect(render(tableoutput.RenderMarkdown)).To(Equal("|  | NAME |\n|---|---|\n| `└─ ` | a |\n"))
+		})
+
+		It("renders html pre elements", func() {
+			Expect(render(tableoutput.RenderHTML)).To(ContainSubstring("<tr><td><pre>└─ </pre></td><td>a</td></tr>\n"))
+		})
+
+		It("renders asciidoc literal columns", func() {
+			Expect(render(tableoutput.RenderAsciiDoc)).To(HavePrefix(`[cols="<l,<",options="header"]`))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package tableoutput_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/manifest"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/sort"
+	"github.com/mandelsoft/flagutils/summary"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// summaryFormatter is a manifest.MetadataFormatter
+// showing only the summary.
+type summaryFormatter struct{}
+
+var _ manifest.MetadataFormatter = summaryFormatter{}
+
+func (f summaryFormatter) Format(ctx context.Context, values []manifest.Manifest) error {
+	return f.FormatWithMetadata(ctx, values, nil)
+}
+
+func (f summaryFormatter) FormatWithMetadata(ctx context.Context, values []manifest.Manifest, meta *manifest.Metadata) error {
+	out.Printf(ctx, "%d items\n", len(values))
+	if meta != nil && meta.Summary != nil {
+		out.Printf(ctx, "total %v\n", meta.Summary.Totals["SIZE"])
+	}
+	return nil
+}
+
+// failingFormatter is a manifest.Formatter failing to format.
+type failingFormatter struct{}
+
+func (f failingFormatter) Format(ctx context.Context, values []manifest.Manifest) error {
+	return fmt.Errorf("cannot format %d items", len(values))
+}
+
+var _ = Describe("summary", func() {
+	var set flagutils.OptionSet
+
+	elements := source{
+		{Name: "b", Size: 1000},
+		{Name: "a", Size: 5},
+	}
+
+	mapper := func(e *Element) output.Fields {
+		return output.Fields{e.Name, fmt.Sprintf("%d", e.Size)}
+	}
+
+	BeforeEach(func() {
+		f := tableoutput.NewOutputFactory[*Element](mapper, "NAME", "-SIZE")
+		outputs := output.NewOutputsFactory[*Element]().
+			Add("", f.WithSummary(output.Total("SIZE"))).
+			Add("avg", f.WithSummary(output.Total("size"), output.Average("size"))).
+			Add("count", f).
+			Add("custom", manifest.NewOutputFactory[*Element](summaryFormatter{}).WithSummary(output.Total("SIZE"))).
+			Add("failing", manifest.NewOutputFactory[*Element](failingFormatter{}))
+		output.AddManifestOutputs(outputs, output.Total("SIZE"), output.Average("SIZE"))
+		set = flagutils.NewOptionSet(sort.New(), summary.New(), tableoutput.New(), output.New(outputs))
+	})
+
+	run := func(args ...string) *flagutilstest.Result {
+		return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			_, err := output.From[*Element](opts).GetOutput().Process(ctx, nil, elements)
+			return err
+		}), args...)
+	}
+
+	It("omits the summary by default", func() {
+		r := run()
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME SIZE\nb    1000\na       5\n"))
+	})
+
+	It("shows totals", func() {
+		r := run("--summary", "-s", "name")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`NAME      SIZE
+a            5
+b         1000
+TOTAL (2) 1005
+`))
+	})
+
+	It("shows averages", func() {
+		r := run("--summary", "-o", "avg")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`NAME       SIZE
+b          1000
+a             5
+TOTAL (2)  1005
+AVERAGE   502.5
+`))
+	})
+
+	It("shows the element count", func() {
+		r := run("--summary", "-o", "count")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("NAME      SIZE\nb         1000\na            5\nTOTAL (2)     \n"))
+	})
+
+	It("adds the summary to the item list", func() {
+		r := run("--summary", "-o", "json")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal(`{"items":[{"name":"b","size":1000},{"name":"a","size":5}],"summary":{"count":2,"totals":{"SIZE":1005},"averages":{"SIZE":502.5}}}`))
+	})
+
+	It("adds a summary document", func() {
+		r := run("--summary", "-o", "yaml")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(HaveSuffix(`---
+summary:
+    count: 2
+    totals:
+        SIZE: 1005
+    averages:
+        SIZE: 502.5
+`))
+	})
+
+	It("shows the summary with summary formatters", func() {
+		r := run("--summary", "-o", "custom")
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stdout).To(Equal("2 items\ntotal 1005\n"))
+	})
+
+	It("reports formatting errors", func() {
+		r := run("--summary", "-o", "failing")
+		Expect(r.RunError).To(MatchError("cannot format 2 items"))
+	})
+})
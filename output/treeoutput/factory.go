@@ -31,7 +31,7 @@ type TreeElement[K, I comparable, O Element[K, I]] interface {
 
 type element[K, I comparable, O Element[K, I]] struct {
 	Element[K, I]
-	fields []string
+	fields output.Values
 }
 
 func (e *element[K, I, O]) GetElement() O {
@@ -39,11 +39,15 @@ func (e *element[K, I, O]) GetElement() O {
 }
 
 func (e *element[K, I, O]) GetFields() []string {
-	return e.fields
+	return e.fields.GetFields()
+}
+
+func (e *element[K, I, O]) GetRawFields() []string {
+	return e.fields.GetRawFields()
 }
 
 func (e *element[K, I, O]) InsertFields(i int, fields ...string) {
-	e.fields = slices.Insert(e.fields, i, fields...)
+	e.fields.InsertFields(i, fields...)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -63,6 +67,26 @@ func (o *OutputFactory[K, I, O]) GetFieldNames(stage string) []string {
 	return nil
 }
 
+// WithRenderer provides a copy of the factory using the given
+// table renderer (see tableoutput.Renderer). The graph column
+// is rendered as preformatted text.
+func (o *OutputFactory[K, I, O]) WithRenderer(r tableoutput.Renderer) *OutputFactory[K, I, O] {
+	return &OutputFactory[K, I, O]{o.OutputFactory.WithRenderer(r), o.dataFields}
+}
+
+// WithSummary provides a copy of the factory declaring the
+// column aggregations shown in the summary rows
+// (see tableoutput.WithSummary).
+func (o *OutputFactory[K, I, O]) WithSummary(fields ...output.SummaryField) *OutputFactory[K, I, O] {
+	return &OutputFactory[K, I, O]{o.OutputFactory.WithSummary(fields...), o.dataFields}
+}
+
+// WithLevel provides a copy of the factory showing the columns
+// up to the given visibility level (see tableoutput.WithLevel).
+func (o *OutputFactory[K, I, O]) WithLevel(level string) *OutputFactory[K, I, O] {
+	return &OutputFactory[K, I, O]{o.OutputFactory.WithLevel(level), o.dataFields}
+}
+
 func NewOutputFactory[K, I comparable, O Element[K, I]](opts *TreeOutputOptions[K], cmp topo.ComparerFactory[O], mapper chain.Mapper[O, output.FieldProvider], headers ...string) *OutputFactory[K, I, O] {
 	c := chain.Transformed[TreeElement[K, I, O], *tree.TreeObject[K]](treeTransform[K, I, O](cmp))
 
@@ -70,13 +94,13 @@ func NewOutputFactory[K, I comparable, O Element[K, I]](opts *TreeOutputOptions[
 		OutputFactory: tableoutput.NewExtendedOutputFactory[O, TreeElement[K, I, O]](
 			func(o O) TreeElement[K, I, O] {
 				return &element[K, I, O]{
-					o, mapper(o).GetFields(),
+					o, output.ComposeValues(mapper(o)),
 				}
 			},
 			chain.AddMap[output.FieldProvider](c, treeMapping[K](len(headers), opts)),
 			output.ComposeFields(opts.Header(), headers)...,
-		),
-		dataFields: headers,
+		).WithFixedColumns(1).WithPreformattedColumns(1),
+		dataFields: tableoutput.FieldNames(headers),
 	}
 }
 
@@ -99,7 +123,7 @@ func treeTransform[K, I comparable, O Element[K, I]](cmp topo.ComparerFactory[O]
 func treeMapping[K comparable](n int, opts *TreeOutputOptions[K]) chain.Mapper[*tree.TreeObject[K], output.FieldProvider] {
 	return func(e *tree.TreeObject[K]) output.FieldProvider {
 		if e.Object != nil {
-			return output.ComposeFields(e.Graph, e.Object)
+			return output.ComposeValues(e.Graph, e.Object)
 		}
 		return output.ComposeFields(e.Graph+" "+opts.NodeTitle(e), opts.NodeMapping(n, e)) // create empty table line
 	}
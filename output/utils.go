@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/output/internal"
 	"github.com/mandelsoft/goutils/sliceutils"
+	"github.com/mandelsoft/streaming/chain"
 	"sort"
 )
 
@@ -50,3 +52,43 @@ func ComposeFields(fields ...interface{}) Fields {
 	}
 	return result
 }
+
+// ComposeValues composes a Values list based on a sequence of Values,
+// strings and field lists. Like ComposeFields, but it keeps the raw values
+// of RawFieldProviders.
+func ComposeValues(fields ...interface{}) Values {
+	var result Values
+	for _, f := range fields {
+		switch v := f.(type) {
+		case Value:
+			result = append(result, v)
+		case Values:
+			result = append(result, v...)
+		case RawFieldProvider:
+			human := v.GetFields()
+			raw := v.GetRawFields()
+			for i := range human {
+				result = append(result, Value{Raw: raw[i], Human: human[i]})
+			}
+		case FieldProvider:
+			for _, e := range v.GetFields() {
+				result = append(result, Plain(e))
+			}
+		case string:
+			result = append(result, Plain(v))
+		case []string:
+			for _, e := range v {
+				result = append(result, Plain(e))
+			}
+		case []interface{}:
+			result = append(result, ComposeValues(v...)...)
+		}
+	}
+	return result
+}
+
+// AddErrorChain adds a step to a chain recording the
+// errors of the elements (see ErrorProvider) with an ErrorReporter.
+func AddErrorChain[I, O any](r *ErrorReporter, c chain.Chain[I, O]) chain.Chain[I, O] {
+	return internal.AddErrorChain(r, c)
+}
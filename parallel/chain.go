@@ -2,6 +2,7 @@ package parallel
 
 import (
 	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/progress"
 	"github.com/mandelsoft/streaming/chain"
 )
 
@@ -10,12 +11,14 @@ import (
 // to chain c.
 // This helper can be used, for example, by output implementations
 // to organize their processing chains.
+// Elements leaving a parallel execution are counted for the
+// progress stage progress.STAGE_PARALLEL.
 func AddParallelChain[N, I, O any](opts flagutils.OptionSetProvider, c chain.Chain[I, O], a chain.Chain[O, N]) chain.Chain[I, N] {
 	o := From(opts)
 	if o != nil {
 		p := o.GetPool()
 		if p != nil {
-			return chain.AddParallel[N](c, a, p)
+			return progress.AddCountChain(progress.GetReporter(opts), progress.STAGE_PARALLEL, chain.AddParallel[N](c, a, p))
 		}
 	}
 	return chain.AddChain[N](c, a)
@@ -15,6 +15,7 @@ type Options struct {
 	poolprovider PoolProvider
 
 	pool processing.Processing
+	size int
 }
 
 func From(opts flagutils.OptionSetProvider) *Options {
@@ -49,12 +50,21 @@ func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flag
 	if n < 0 {
 		return fmt.Errorf("invalid degree of parallelism: %d", n)
 	}
+	if o.pool != nil && o.size != n {
+		// the degree has been changed, for example, interactively.
+		err := o.pool.Close()
+		o.pool = nil
+		if err != nil {
+			return err
+		}
+	}
 	if o.pool == nil && n > 1 {
 		if o.poolprovider != nil {
 			o.pool = o.poolprovider(ctx, n)
 		} else {
 			o.pool = simplepool.New(ctx, n)
 		}
+		o.size = n
 	}
 	return nil
 }
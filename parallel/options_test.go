@@ -0,0 +1,57 @@
+package parallel_test
+
+import (
+	"context"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/streaming/processing"
+	"github.com/mandelsoft/streaming/simplepool"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/parallel"
+)
+
+var _ = Describe("parallel options", func() {
+	var ctx context.Context
+	var sizes []int
+	var opts *parallel.Options
+	var set flagutils.OptionSet
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sizes = nil
+		opts = parallel.New(2).WithPoolProvider(func(ctx context.Context, n int) processing.Processing {
+			sizes = append(sizes, n)
+			return simplepool.New(ctx, n)
+		})
+		set = flagutils.NewOptionSet(opts)
+	})
+
+	AfterEach(func() {
+		MustBeSuccessful(flagutils.Finalize(ctx, set, nil))
+		Expect(opts.GetPool()).To(BeNil())
+	})
+
+	It("keeps the pool for repeated validations", func() {
+		MustBeSuccessful(flagutils.Validate(ctx, set, nil))
+		pool := opts.GetPool()
+		Expect(pool).NotTo(BeNil())
+		MustBeSuccessful(flagutils.Validate(ctx, set, nil))
+		Expect(opts.GetPool()).To(BeIdenticalTo(pool))
+		Expect(sizes).To(Equal([]int{2}))
+	})
+
+	It("rebuilds the pool for a changed degree", func() {
+		MustBeSuccessful(flagutils.Validate(ctx, set, nil))
+		opts.Set(3)
+		MustBeSuccessful(flagutils.Validate(ctx, set, nil))
+		Expect(sizes).To(Equal([]int{2, 3}))
+
+		opts.Set(1)
+		MustBeSuccessful(flagutils.Validate(ctx, set, nil))
+		Expect(opts.GetPool()).To(BeNil())
+	})
+})
@@ -0,0 +1,137 @@
+package pflags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// SecretMask is the string representation of a non-empty secret value.
+const SecretMask = "***"
+
+// secretValue is a flag value for secrets like tokens or passwords.
+// Its value is never provided by String. A value may be given literally,
+// by a file (@<path>), an environment variable (env:<name>) or
+// standard input (-).
+type secretValue struct {
+	value *[]byte
+	stdin io.Reader
+	// owned is the last value allocated by Set. Only such values
+	// are zeroed, other values, like the default, belong to the caller.
+	owned []byte
+}
+
+// NewSecretValue creates a secret flag value for the given variable.
+// The value - reads the secret from the given reader,
+// which defaults to os.Stdin. Values replaced by Set are zeroed,
+// except the given default value, which belongs to the caller.
+func NewSecretValue(p *[]byte, value []byte, stdin io.Reader) pflag.Value {
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	*p = value
+	return &secretValue{value: p, stdin: stdin}
+}
+
+func (s *secretValue) Set(val string) error {
+	var data []byte
+	switch {
+	case val == "-":
+		d, err := io.ReadAll(s.stdin)
+		if err != nil {
+			return fmt.Errorf("cannot read secret from stdin: %w", err)
+		}
+		data = trimNewline(d)
+	case strings.HasPrefix(val, "@"):
+		d, err := os.ReadFile(val[1:])
+		if err != nil {
+			return fmt.Errorf("cannot read secret file: %w", err)
+		}
+		data = trimNewline(d)
+	case strings.HasPrefix(val, "env:"):
+		v, ok := os.LookupEnv(val[4:])
+		if !ok {
+			return fmt.Errorf("environment variable %q not set", val[4:])
+		}
+		data = []byte(v)
+	default:
+		data = []byte(val)
+	}
+	s.clear()
+	*s.value = data
+	s.owned = data
+	return nil
+}
+
+// clear zeroes the value allocated by Set, even if the variable
+// has been replaced meanwhile, and resets the variable.
+func (s *secretValue) clear() {
+	ZeroBytes(s.owned)
+	*s.value = nil
+	s.owned = nil
+}
+
+func (s *secretValue) Type() string {
+	return "secret"
+}
+
+func (s *secretValue) String() string {
+	if len(*s.value) == 0 {
+		return ""
+	}
+	return SecretMask
+}
+
+// IsSecret marks the value as secret.
+func (s *secretValue) IsSecret() bool {
+	return true
+}
+
+func trimNewline(data []byte) []byte {
+	n := len(data)
+	for n > 0 && (data[n-1] == '\n' || data[n-1] == '\r') {
+		n--
+	}
+	return data[:n]
+}
+
+// ClearSecret resets the variable of a secret flag value. The memory of
+// a value allocated by the flag (given literally, by a file, an
+// environment variable or standard input) is zeroed.
+// Values provided by the caller are kept unchanged.
+// It reports whether the value is a secret flag value.
+func ClearSecret(v pflag.Value) bool {
+	if s, ok := v.(*secretValue); ok {
+		s.clear()
+		return true
+	}
+	return false
+}
+
+// ZeroBytes overwrites a byte slice with zeros.
+func ZeroBytes(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
+
+// SecretVarP defines a secret flag. The default value is never shown in help.
+func SecretVarP(f *pflag.FlagSet, p *[]byte, name, shorthand string, value []byte, usage string) {
+	SecretVarPF(f, p, name, shorthand, value, usage)
+}
+
+// SecretVarPF is like SecretVarP, but returns the created flag.
+func SecretVarPF(f *pflag.FlagSet, p *[]byte, name, shorthand string, value []byte, usage string) *pflag.Flag {
+	return SecretInputVarPF(f, p, nil, name, shorthand, value, usage)
+}
+
+// SecretInputVarPF is like SecretVarPF, but uses the given reader
+// instead of os.Stdin for the value -.
+func SecretInputVarPF(f *pflag.FlagSet, p *[]byte, stdin io.Reader, name, shorthand string, value []byte, usage string) *pflag.Flag {
+	flag := f.VarPF(NewSecretValue(p, value, stdin), name, shorthand, usage)
+	flag.DefValue = ""
+	return flag
+}
@@ -0,0 +1,62 @@
+package pflags
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("secret flags", func() {
+	var flags *pflag.FlagSet
+	var flag []byte
+
+	BeforeEach(func() {
+		flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		SecretInputVarPF(flags, &flag, strings.NewReader("from stdin\n"), "token", "", []byte("default"), "access token")
+	})
+
+	It("masks the value", func() {
+		Expect(flags.Parse([]string{"--token", "value"})).To(Succeed())
+		Expect(string(flag)).To(Equal("value"))
+		Expect(flags.Lookup("token").Value.String()).To(Equal(SecretMask))
+		Expect(flags.FlagUsages()).To(Equal("      --token secret   access token\n"))
+	})
+
+	It("reads stdin", func() {
+		Expect(flags.Parse([]string{"--token", "-"})).To(Succeed())
+		Expect(string(flag)).To(Equal("from stdin"))
+	})
+
+	It("reads files", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(path, []byte("from file\n"), 0o600)).To(Succeed())
+		Expect(flags.Parse([]string{"--token", "@" + path})).To(Succeed())
+		Expect(string(flag)).To(Equal("from file"))
+	})
+
+	It("reads environment variables", func() {
+		GinkgoT().Setenv("FLAGTEST_TOKEN", "from env")
+		Expect(flags.Parse([]string{"--token", "env:FLAGTEST_TOKEN"})).To(Succeed())
+		Expect(string(flag)).To(Equal("from env"))
+		Expect(flags.Parse([]string{"--token", "env:FLAGTEST_UNKNOWN"})).To(MatchError(ContainSubstring(`environment variable "FLAGTEST_UNKNOWN" not set`)))
+	})
+
+	It("zeroes replaced values", func() {
+		Expect(flags.Parse([]string{"--token", "value"})).To(Succeed())
+		old := flag
+		Expect(flags.Parse([]string{"--token", "other"})).To(Succeed())
+		Expect(old).To(Equal(make([]byte, len("value"))))
+		Expect(string(flag)).To(Equal("other"))
+	})
+
+	It("keeps the default value", func() {
+		def := flag
+		Expect(flags.Parse([]string{"--token", "value"})).To(Succeed())
+		Expect(string(def)).To(Equal("default"))
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/maputils"
 	"github.com/spf13/pflag"
 )
 
@@ -52,6 +53,15 @@ func (s *stringColonStringSliceValue[T]) Type() string {
 	return "<name>:<value>,<value>,..."
 }
 
+// GetAssignments provides the map entries in the format accepted by Set.
+func (s *stringColonStringSliceValue[T]) GetAssignments() []string {
+	var list []string
+	for _, k := range maputils.OrderedKeys(*s.value) {
+		list = append(list, k+":"+strings.Join((*s.value)[k], ","))
+	}
+	return list
+}
+
 func (s *stringColonStringSliceValue[T]) String() string {
 	records := make([]string, 0, len(*s.value))
 	for k, v := range *s.value {
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mandelsoft/goutils/maputils"
 	"github.com/spf13/pflag"
 )
 
@@ -76,6 +77,21 @@ func (s *stringToStringValue[T]) Type() string {
 	return "<name>=<value>"
 }
 
+// GetAssignments provides the map entries in the format accepted by Set.
+func (s *stringToStringValue[T]) GetAssignments() []string {
+	var list []string
+	for _, k := range maputils.OrderedKeys(*s.value) {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write([]string{k + "=" + (*s.value)[k]}); err != nil {
+			panic(err)
+		}
+		w.Flush()
+		list = append(list, strings.TrimSpace(buf.String()))
+	}
+	return list
+}
+
 func (s *stringToStringValue[T]) String() string {
 	records := make([]string, 0, len(*s.value)>>1)
 	for k, v := range *s.value {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/mandelsoft/goutils/maputils"
 	"github.com/spf13/pflag"
 )
 
@@ -49,6 +50,15 @@ func (s *stringToStringSliceValue[T]) Type() string {
 	return "<name>=<value>,<value>,..."
 }
 
+// GetAssignments provides the map entries in the format accepted by Set.
+func (s *stringToStringSliceValue[T]) GetAssignments() []string {
+	var list []string
+	for _, k := range maputils.OrderedKeys(*s.value) {
+		list = append(list, k+"="+strings.Join((*s.value)[k], ","))
+	}
+	return list
+}
+
 func (s *stringToStringSliceValue[T]) String() string {
 	records := make([]string, 0, len(*s.value))
 	for k, v := range *s.value {
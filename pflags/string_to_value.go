@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mandelsoft/goutils/maputils"
 	"github.com/spf13/pflag"
 )
 
@@ -45,6 +46,17 @@ func (s *valueStringToValue) Type() string {
 	return "<name>=<YAML>"
 }
 
+// GetAssignments provides the map entries in the format accepted by Set.
+func (s *valueStringToValue) GetAssignments() []string {
+	var list []string
+	for _, k := range maputils.OrderedKeys(*s.value) {
+		//nolint: errchkjson // initialized by unmarshal
+		v, _ := json.Marshal((*s.value)[k])
+		list = append(list, k+"="+string(v))
+	}
+	return list
+}
+
 func (s *valueStringToValue) String() string {
 	if *s.value == nil {
 		return ""
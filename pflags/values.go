@@ -0,0 +1,22 @@
+package pflags
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// MapValue is implemented by flag values for map types.
+// GetAssignments provides the entries of the map as ordered list
+// of strings accepted by the Set method of the value.
+// Because the Set method incrementally adds entries after its first call,
+// the complete value can be restored by setting all assignments.
+type MapValue interface {
+	pflag.Value
+	GetAssignments() []string
+}
+
+var (
+	_ MapValue = (*stringToStringValue[map[string]string])(nil)
+	_ MapValue = (*stringToStringSliceValue[map[string][]string])(nil)
+	_ MapValue = (*stringColonStringSliceValue[map[string][]string])(nil)
+	_ MapValue = (*valueStringToValue)(nil)
+)
@@ -0,0 +1,115 @@
+package flagutils
+
+import (
+	"fmt"
+
+	"github.com/mandelsoft/goutils/general"
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/spf13/pflag"
+)
+
+// ShorthandPolicy maps the shorthand of a flag registered by an Options object
+// wrapped by a PrefixedOptions object. An empty result omits the shorthand.
+type ShorthandPolicy func(prefix, shorthand string) string
+
+// DropShorthands omits all shorthands of prefixed flags.
+// This is the default policy, because the same shorthand
+// would be registered by multiple instances of the same Options type.
+func DropShorthands(prefix, shorthand string) string {
+	return ""
+}
+
+// KeepShorthands keeps the original shorthands.
+// It should only be used for a single instance of an Options type.
+func KeepShorthands(prefix, shorthand string) string {
+	return shorthand
+}
+
+// MappedShorthands provides a ShorthandPolicy using an explicit mapping
+// of original shorthands. Unmapped shorthands are omitted.
+func MappedShorthands(mapping map[string]string) ShorthandPolicy {
+	return func(prefix, shorthand string) string {
+		return mapping[shorthand]
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// PrefixedInstance is the type-independent interface of PrefixedOptions.
+type PrefixedInstance interface {
+	Options
+	GetPrefix() string
+	Unwrap() Options
+}
+
+// PrefixedOptions instantiates an Options object under a name prefix.
+// All flags registered by the wrapped object are renamed to
+// <prefix>-<name> and their shorthands are mapped by a ShorthandPolicy.
+// This way, the same Options type can be used multiple times in a single
+// OptionSet, for example, for a source and a target repository.
+// The lifecycle methods of the wrapped object are reached by unwrapping.
+// Wrapped objects are found by GetFrom and Filter. GetFrom accepts
+// the prefix as instance key to select a dedicated instance.
+type PrefixedOptions[T Options] struct {
+	prefix  string
+	policy  ShorthandPolicy
+	Options T
+}
+
+var (
+	_ PrefixedInstance              = (*PrefixedOptions[Options])(nil)
+	_ OptionsDescriber              = (*PrefixedOptions[Options])(nil)
+	_ generics.Unwrappable[Options] = (*PrefixedOptions[Options])(nil)
+)
+
+// NewPrefixedOptions wraps the given Options under the given prefix.
+// By default, shorthands are dropped (see DropShorthands).
+func NewPrefixedOptions[T Options](prefix string, o T, policy ...ShorthandPolicy) *PrefixedOptions[T] {
+	return &PrefixedOptions[T]{prefix: prefix, policy: general.OptionalDefaulted[ShorthandPolicy](DropShorthands, policy...), Options: o}
+}
+
+func (o *PrefixedOptions[T]) GetPrefix() string {
+	return o.prefix
+}
+
+func (o *PrefixedOptions[T]) Unwrap() Options {
+	return o.Options
+}
+
+func (o *PrefixedOptions[T]) DescribeOptions() string {
+	return o.describe(DescribeOptions(o.Options))
+}
+
+func (o *PrefixedOptions[T]) describe(desc string) string {
+	return fmt.Sprintf("%s[%s]", desc, o.prefix)
+}
+
+func (o *PrefixedOptions[T]) AddFlags(fs *pflag.FlagSet) {
+	tmp := pflag.NewFlagSet(o.prefix, pflag.ContinueOnError)
+	tmp.SortFlags = false
+	o.Options.AddFlags(tmp)
+	tmp.VisitAll(func(f *pflag.Flag) {
+		f.Name = PrefixedName(o.prefix, f.Name)
+		if t := FlagAliasTarget(f); t != nil {
+			f.Deprecated = aliasDeprecation(t)
+		}
+		if f.Shorthand != "" {
+			f.Shorthand = o.policy(o.prefix, f.Shorthand)
+		}
+		desc := GetFlagOptions(f)
+		if desc == "" {
+			desc = DescribeOptions(o.Options)
+		}
+		setFlagOptions(f, o.describe(desc), o.Options)
+		fs.AddFlag(f)
+	})
+}
+
+// PrefixedName provides the flag name for a flag name used
+// by an Options object instantiated with a prefix.
+func PrefixedName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "-" + name
+}
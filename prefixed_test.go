@@ -0,0 +1,86 @@
+package flagutils_test
+
+import (
+	"context"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/parallel"
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("prefixed options", func() {
+	var set flagutils.ExtendableOptionSet
+	var fs *pflag.FlagSet
+
+	BeforeEach(func() {
+		set = flagutils.NewOptionSet()
+		fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	})
+
+	It("instantiates the same type twice", func() {
+		set.Add(
+			flagutils.NewPrefixedOptions("source", parallel.New()),
+			flagutils.NewPrefixedOptions("target", parallel.New()),
+		)
+		set.AddFlags(fs)
+
+		Expect(fs.Lookup("source-parallel")).NotTo(BeNil())
+		Expect(fs.Lookup("source-parallel").Shorthand).To(Equal(""))
+		Expect(fs.Lookup("target-parallel")).NotTo(BeNil())
+		Expect(fs.Lookup("parallel")).To(BeNil())
+
+		MustBeSuccessful(fs.Parse([]string{"--source-parallel", "2", "--target-parallel=3"}))
+		Expect(flagutils.GetFrom[*parallel.Options](set, "source").Value()).To(Equal(2))
+		Expect(flagutils.GetFrom[*parallel.Options](set, "target").Value()).To(Equal(3))
+		Expect(flagutils.GetFrom[*parallel.Options](set, "other")).To(BeNil())
+		Expect(flagutils.GetFrom[*parallel.Options](set, "")).To(BeNil())
+		Expect(parallel.From(set)).To(BeIdenticalTo(flagutils.GetFrom[*parallel.Options](set, "source")))
+		Expect(flagutils.Filter[*parallel.Options](set)).To(HaveLen(2))
+	})
+
+	It("selects unprefixed instances by the empty key", func() {
+		p := parallel.New()
+		set.Add(flagutils.NewPrefixedOptions("source", parallel.New()), p)
+		Expect(flagutils.GetFrom[*parallel.Options](set, "")).To(BeIdenticalTo(p))
+	})
+
+	It("selects nested instances by the combined prefix", func() {
+		set.Add(flagutils.NewPrefixedOptions("repo", flagutils.NewPrefixedOptions("source", parallel.New())))
+		set.AddFlags(fs)
+
+		MustBeSuccessful(fs.Parse([]string{"--repo-source-parallel", "2"}))
+		Expect(flagutils.GetFrom[*parallel.Options](set, "repo-source").Value()).To(Equal(2))
+	})
+
+	It("provides the context of wrapped options", func() {
+		set.Add(flagutils.NewPrefixedOptions("source", &contextOption{}))
+		ctx := Must(flagutils.RunContext(context.Background(), set))
+		Expect(ctx.Value("provided")).To(Equal("value"))
+	})
+
+	It("applies shorthand policy", func() {
+		set.Add(
+			flagutils.NewPrefixedOptions("source", &TestOption{}, flagutils.KeepShorthands),
+			flagutils.NewPrefixedOptions("target", &TestOption{}, flagutils.MappedShorthands(map[string]string{"t": "T"})),
+		)
+		set.AddFlags(fs)
+
+		MustBeSuccessful(fs.Parse([]string{"-t"}))
+		Expect(flagutils.GetFrom[*TestOption](set, "source").Flag).To(BeTrue())
+		Expect(flagutils.GetFrom[*TestOption](set, "target").Flag).To(BeFalse())
+		MustBeSuccessful(fs.Parse([]string{"-T"}))
+		Expect(flagutils.GetFrom[*TestOption](set, "target").Flag).To(BeTrue())
+	})
+
+	It("forwards lifecycle", func() {
+		set.Add(flagutils.NewPrefixedOptions("source", &TestOption{}))
+		MustBeSuccessful(flagutils.Validate(context.Background(), set, nil))
+		MustBeSuccessful(flagutils.Finalize(context.Background(), set, nil))
+		o := flagutils.GetFrom[*TestOption](set, "source")
+		Expect(o.Validated).To(BeTrue())
+		Expect(o.Finalized).To(BeTrue())
+	})
+})
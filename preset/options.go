@@ -0,0 +1,175 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mandelsoft/goutils/maputils"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+type Options struct {
+	flagutils.SimpleOption[string, *Options]
+
+	builtin []flagutils.Preset
+	files   []string
+	presets map[string]flagutils.Preset
+	fs      *pflag.FlagSet
+}
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+var (
+	_ flagutils.Options        = (*Options)(nil)
+	_ flagutils.Preparable     = (*Options)(nil)
+	_ flagutils.PresetProvider = (*Options)(nil)
+	_ flagutils.Usage          = (*Options)(nil)
+	_ flagutils.FlagSetBinder  = (*Options)(nil)
+)
+
+// New creates preset Options for the given presets.
+// Additional presets are taken from config files and
+// from other Options objects implementing flagutils.PresetProvider.
+func New(presets ...flagutils.Preset) *Options {
+	o := &Options{builtin: slices.Clone(presets), presets: map[string]flagutils.Preset{}}
+	o.SimpleOption = flagutils.NewSimpleOptionWithSetter[string](o, o.varP, "", "profile", "", "select named option preset (%s)")
+	return o
+}
+
+func (o *Options) WithPresets(presets ...flagutils.Preset) *Options {
+	o.builtin = append(o.builtin, presets...)
+	return o
+}
+
+// WithConfigFile adds config files (see Config) used to read
+// additional presets. Presets from config files override
+// built-in presets with the same name.
+func (o *Options) WithConfigFile(paths ...string) *Options {
+	o.files = append(o.files, paths...)
+	return o
+}
+
+func (o *Options) Prepare(ctx context.Context, opts flagutils.OptionSet, v flagutils.PreparationSet) error {
+	for _, p := range flagutils.Filter[flagutils.PresetProvider](opts) {
+		if p == flagutils.PresetProvider(o) {
+			continue
+		}
+		o.add(p.GetPresets()...)
+	}
+	o.add(o.builtin...)
+	for _, f := range o.files {
+		if f == "" {
+			continue
+		}
+		list, err := ReadConfig(f)
+		if err != nil {
+			return err
+		}
+		o.add(list...)
+	}
+	return nil
+}
+
+func (o *Options) add(presets ...flagutils.Preset) {
+	for _, p := range presets {
+		o.presets[p.Name] = p
+	}
+}
+
+func (o *Options) GetPresets() []flagutils.Preset {
+	var list []flagutils.Preset
+	for _, n := range maputils.OrderedKeys(o.presets) {
+		list = append(list, o.presets[n])
+	}
+	return list
+}
+
+func (o *Options) GetPreset(name string) (flagutils.Preset, bool) {
+	p, ok := o.presets[name]
+	return p, ok
+}
+
+func (o *Options) Usage() string {
+	if len(o.presets) == 0 {
+		return ""
+	}
+	s := "\nThe following option presets are available:\n"
+	for _, p := range o.GetPresets() {
+		s += fmt.Sprintf("  - %s: %s (%s)\n", p.Name, p.Description, strings.Join(p.Args, " "))
+	}
+	return s
+}
+
+func (o *Options) varP(fs *pflag.FlagSet, p *string, name, shorthand string, value string, usage string) {
+	*p = value
+	if strings.Contains(usage, "%s") {
+		usage = fmt.Sprintf(usage, strings.Join(maputils.OrderedKeys(o.presets), ", "))
+	}
+	o.fs = fs
+	fs.VarP(&presetValue{options: o, value: p}, name, shorthand, usage)
+}
+
+// BindFlagSet sets the flag set presets are expanded for.
+func (o *Options) BindFlagSet(fs *pflag.FlagSet) {
+	o.fs = fs
+}
+
+// presetValue expands a preset when the flag is set.
+// During parsing by flagutils.Parse, the expansion is deferred until
+// all command line arguments are parsed, so that explicitly given
+// flags override the preset values regardless of their position.
+// Otherwise, the preset is expanded immediately.
+type presetValue struct {
+	options *Options
+	value   *string
+	parsing bool
+	pending []flagutils.Preset
+}
+
+var _ flagutils.ParseCompleter = (*presetValue)(nil)
+
+func (p *presetValue) Set(name string) error {
+	preset, ok := p.options.presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset %q (possible presets are %s)", name, strings.Join(maputils.OrderedKeys(p.options.presets), ", "))
+	}
+	*p.value = name
+	if p.parsing {
+		p.pending = append(p.pending, preset)
+		return nil
+	}
+	return Expand(p.options.fs, preset)
+}
+
+func (p *presetValue) BeginParse(fs *pflag.FlagSet) {
+	p.parsing = true
+}
+
+// CompleteParse expands the presets selected during parsing.
+// They are expanded even if parsing failed, so that no preset
+// is dropped silently.
+func (p *presetValue) CompleteParse(fs *pflag.FlagSet) error {
+	p.parsing = false
+	pending := p.pending
+	p.pending = nil
+	for _, preset := range pending {
+		if err := Expand(p.options.fs, preset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *presetValue) Type() string {
+	return "string"
+}
+
+func (p *presetValue) String() string {
+	return *p.value
+}
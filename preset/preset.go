@@ -0,0 +1,79 @@
+package preset
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/set"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+// Config is the format of a preset config file.
+type Config struct {
+	Presets []flagutils.Preset `json:"presets"`
+}
+
+// UserConfigFile provides the default location of the preset config
+// file for an application in the user's config directory.
+func UserConfigFile(app string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, app, "presets.yaml")
+}
+
+// ReadConfig reads the presets from a config file.
+// A non-existing file provides an empty list.
+func ReadConfig(path string) ([]flagutils.Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "invalid preset config %q", path)
+	}
+	return cfg.Presets, nil
+}
+
+// Expand sets the flag values described by a preset for a pflag.FlagSet.
+// Flags already changed are kept, the preset values for them are ignored.
+// Slice values given by the preset replace the default value.
+func Expand(fs *pflag.FlagSet, p flagutils.Preset) error {
+	explicit := set.New[string]()
+	scratch := pflag.NewFlagSet(p.Name, pflag.ContinueOnError)
+	scratch.SetOutput(io.Discard)
+	scratch.Usage = func() {}
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			explicit.Add(f.Name)
+		}
+		scratch.AddFlag(f)
+	})
+
+	origin := flagutils.NewOrigin(flagutils.ORIGIN_PRESET, p.Name)
+	err := scratch.ParseAll(p.Args, func(f *pflag.Flag, value string) error {
+		if explicit.Has(f.Name) {
+			return nil
+		}
+		return flagutils.SetFlag(fs, f.Name, value, origin)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "preset %q", p.Name)
+	}
+	if scratch.NArg() > 0 {
+		return fmt.Errorf("preset %q: unexpected arguments %v", p.Name, scratch.Args())
+	}
+	return nil
+}
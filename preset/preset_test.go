@@ -0,0 +1,121 @@
+package preset_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/preset"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func setup(p *preset.Options) (flagutils.OptionSet, *pflag.FlagSet) {
+	outputs := output.AddPreset(output.NewOutputsFactory[int](), "wide", "show all columns", "--all-columns")
+	opts := flagutils.NewOptionSet(
+		p,
+		output.New(outputs),
+		tableoutput.New().WithOptimizedColumns(2),
+		parallel.New(),
+	)
+	MustBeSuccessful(flagutils.Prepare(context.Background(), opts, nil))
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(fs)
+	return opts, fs
+}
+
+var _ = Describe("presets", func() {
+	It("expands a preset", func() {
+		opts, fs := setup(preset.New(flagutils.NewPreset("fast", "parallel table", "-p", "10", "--columns", "name,size")))
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--profile", "fast"}))
+		Expect(preset.From(opts).Value()).To(Equal("fast"))
+		Expect(parallel.From(opts).Value()).To(Equal(10))
+		Expect(tableoutput.From(opts).UseColumns()).To(Equal([]string{"name", "size"}))
+		Expect(flagutils.FlagOrigin(fs.Lookup("parallel"))).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_PRESET, "fast")))
+	})
+
+	It("keeps explicit flags", func() {
+		opts, fs := setup(preset.New(flagutils.NewPreset("fast", "parallel table", "-p", "10", "--columns", "name,size")))
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "2", "--profile", "fast", "--columns", "name"}))
+		Expect(parallel.From(opts).Value()).To(Equal(2))
+		Expect(tableoutput.From(opts).UseColumns()).To(Equal([]string{"name"}))
+		Expect(flagutils.FlagOrigin(fs.Lookup("parallel"))).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+		Expect(flagutils.FlagOrigin(fs.Lookup("columns"))).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+	})
+
+	It("keeps explicit flags given before", func() {
+		opts, fs := setup(preset.New(flagutils.NewPreset("fast", "parallel table", "-p", "10", "--columns", "name,size")))
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--columns", "name", "--profile", "fast", "--columns", "size"}))
+		Expect(parallel.From(opts).Value()).To(Equal(10))
+		Expect(tableoutput.From(opts).UseColumns()).To(Equal([]string{"name", "size"}))
+		Expect(flagutils.FlagOrigin(fs.Lookup("columns"))).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+	})
+
+	It("expands a preset immediately without flagutils.Parse", func() {
+		opts, fs := setup(preset.New(flagutils.NewPreset("fast", "parallel table", "-p", "10", "--columns", "name,size")))
+
+		MustBeSuccessful(fs.Parse([]string{"-p", "2", "--profile", "fast"}))
+		Expect(parallel.From(opts).Value()).To(Equal(2))
+		Expect(tableoutput.From(opts).UseColumns()).To(Equal([]string{"name", "size"}))
+		Expect(flagutils.FlagOrigin(fs.Lookup("columns"))).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_PRESET, "fast")))
+	})
+
+	It("expands a preset set after parsing", func() {
+		opts, fs := setup(preset.New(flagutils.NewPreset("fast", "parallel table", "-p", "10", "--columns", "name,size")))
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "2"}))
+		MustBeSuccessful(flagutils.SetFlag(fs, "profile", "fast", flagutils.NewOrigin(flagutils.ORIGIN_INTERACTIVE)))
+		Expect(parallel.From(opts).Value()).To(Equal(2))
+		Expect(tableoutput.From(opts).UseColumns()).To(Equal([]string{"name", "size"}))
+	})
+
+	It("uses contributed presets", func() {
+		opts, fs := setup(preset.New())
+
+		Expect(fs.Lookup("profile").Usage).To(Equal("select named option preset (wide)"))
+		Expect(preset.From(opts).Usage()).To(Equal("\nThe following option presets are available:\n  - wide: show all columns (--all-columns)\n"))
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--profile", "wide"}))
+		Expect(tableoutput.From(opts).UseAllColumns()).To(BeTrue())
+	})
+
+	It("reads presets from config file", func() {
+		dir := Must(os.MkdirTemp("", "presets"))
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "presets.yaml")
+		MustBeSuccessful(os.WriteFile(path, []byte(`
+presets:
+- name: wide
+  description: my wide
+  args: ["-p", "5", "--all-columns"]
+`), 0o600))
+
+		opts, fs := setup(preset.New().WithConfigFile(path, filepath.Join(dir, "missing.yaml")))
+		p, ok := preset.From(opts).GetPreset("wide")
+		Expect(ok).To(BeTrue())
+		Expect(p.Description).To(Equal("my wide"))
+		MustBeSuccessful(flagutils.Parse(fs, []string{"--profile", "wide"}))
+		Expect(parallel.From(opts).Value()).To(Equal(5))
+		Expect(tableoutput.From(opts).UseAllColumns()).To(BeTrue())
+	})
+
+	It("rejects unknown presets", func() {
+		_, fs := setup(preset.New())
+		Expect(flagutils.Parse(fs, []string{"--profile", "other"})).To(MatchError(ContainSubstring(`unknown preset "other" (possible presets are wide)`)))
+	})
+
+	It("rejects invalid preset arguments", func() {
+		_, fs := setup(preset.New(flagutils.NewPreset("bad", "", "--unknown")))
+		Expect(flagutils.Parse(fs, []string{"--profile", "bad"})).To(MatchError(ContainSubstring(`preset "bad": unknown flag: --unknown`)))
+	})
+})
@@ -0,0 +1,19 @@
+package flagutils
+
+// Preset is a named list of command line arguments
+// used to preset flag values.
+type Preset struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Args        []string `json:"args"`
+}
+
+func NewPreset(name, desc string, args ...string) Preset {
+	return Preset{Name: name, Description: desc, Args: args}
+}
+
+// PresetProvider is an optional interface for Options objects
+// able to contribute built-in presets.
+type PresetProvider interface {
+	GetPresets() []Preset
+}
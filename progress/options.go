@@ -0,0 +1,74 @@
+package progress
+
+import (
+	"context"
+	"time"
+
+	"github.com/mandelsoft/goutils/general"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+// Options is the --progress option enabling a progress
+// Reporter for the processing chains of the outputs.
+type Options struct {
+	flagutils.SimpleOption[bool, *Options]
+	interval time.Duration
+	always   bool
+	reporter *Reporter
+}
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+var (
+	_ flagutils.Options     = (*Options)(nil)
+	_ flagutils.Validatable = (*Options)(nil)
+)
+
+// New creates a progress option. Optionally, the
+// progress can be enabled by default.
+func New(enabled ...bool) *Options {
+	o := &Options{}
+	o.SimpleOption = flagutils.NewSimpleOption(o, general.Optional(enabled...), "progress", "", "show progress on stderr")
+	return o
+}
+
+// WithInterval sets the refresh interval of the progress line.
+func (o *Options) WithInterval(d time.Duration) *Options {
+	o.interval = d
+	return o
+}
+
+// WithAlways enables the progress reporting even if
+// stderr is no terminal.
+func (o *Options) WithAlways() *Options {
+	o.always = true
+	return o
+}
+
+func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flagutils.ValidationSet) error {
+	if o.Value() {
+		o.reporter = NewReporter(o.interval, o.always)
+	} else {
+		o.reporter = nil
+	}
+	return nil
+}
+
+// GetReporter provides the Reporter, if progress
+// reporting is enabled.
+func (o *Options) GetReporter() *Reporter {
+	return o.reporter
+}
+
+// GetReporter provides the Reporter of an option set,
+// or nil if progress reporting is not enabled.
+func GetReporter(opts flagutils.OptionSetProvider) *Reporter {
+	o := From(opts)
+	if o == nil {
+		return nil
+	}
+	return o.GetReporter()
+}
@@ -0,0 +1,153 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/streaming/chain"
+
+	"github.com/mandelsoft/flagutils/prompt"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+// Stages counted by the predefined processing chains.
+const (
+	STAGE_PRODUCED  = "produced"
+	STAGE_PARALLEL  = "parallel"
+	STAGE_PROCESSED = "processed"
+)
+
+// DEFAULT_INTERVAL is the default refresh interval of a Reporter.
+const DEFAULT_INTERVAL = 100 * time.Millisecond
+
+var spinner = []rune(`|/-\`)
+
+// Reporter counts the elements passing the stages of a processing
+// chain and periodically shows the counts and the elapsed time on the
+// stderr of an output context (see package utils/out) while running.
+// All methods can be called on a nil Reporter.
+type Reporter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	always   bool
+	stages   []string
+	counts   map[string]int
+
+	start   time.Time
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewReporter creates a Reporter. By default, it only reports
+// if stderr is a terminal.
+func NewReporter(interval time.Duration, always bool) *Reporter {
+	if interval <= 0 {
+		interval = DEFAULT_INTERVAL
+	}
+	return &Reporter{interval: interval, always: always, counts: map[string]int{}}
+}
+
+// Count counts an element for the given stage.
+func (r *Reporter) Count(stage string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.counts[stage]; !ok {
+		r.stages = append(r.stages, stage)
+	}
+	r.counts[stage]++
+}
+
+// Get provides the count for a stage.
+func (r *Reporter) Get(stage string) int {
+	if r == nil {
+		return 0
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.counts[stage]
+}
+
+// Start resets the counts and starts the reporting on the stderr of
+// the output context. This way, a Reporter shared by several outputs
+// reports the processing of every output separately.
+func (r *Reporter) Start(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	w := out.Get(ctx).Stderr()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.done != nil {
+		return
+	}
+	r.stages = nil
+	r.counts = map[string]int{}
+	r.start = time.Now()
+	if !r.always && !prompt.IsTerminal(w) {
+		return
+	}
+	r.done = make(chan struct{})
+	r.stopped = make(chan struct{})
+	go r.report(w, r.done, r.stopped)
+}
+
+// Stop stops the reporting and clears the progress line.
+func (r *Reporter) Stop() {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	done, stopped := r.done, r.stopped
+	r.done = nil
+	r.lock.Unlock()
+	if done != nil {
+		close(done)
+		<-stopped
+	}
+}
+
+func (r *Reporter) report(w io.Writer, done, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			fmt.Fprint(w, "\r\033[K")
+			return
+		case <-ticker.C:
+			fmt.Fprintf(w, "\r%c %s\033[K", spinner[i%len(spinner)], r.line())
+		}
+	}
+}
+
+func (r *Reporter) line() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var list []string
+	for _, s := range r.stages {
+		list = append(list, fmt.Sprintf("%s %d", s, r.counts[s]))
+	}
+	list = append(list, fmt.Sprintf("elapsed %s", time.Since(r.start).Round(100*time.Millisecond)))
+	return strings.Join(list, ", ")
+}
+
+// AddCountChain adds a step to a chain counting the elements
+// for the given stage. Without a Reporter the chain is unchanged.
+func AddCountChain[I, O any](r *Reporter, stage string, c chain.Chain[I, O]) chain.Chain[I, O] {
+	if r == nil {
+		return c
+	}
+	return chain.AddMap[O](c, func(e O) O {
+		r.Count(stage)
+		return e
+	})
+}
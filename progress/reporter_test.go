@@ -0,0 +1,119 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/output"
+	"github.com/mandelsoft/flagutils/output/tableoutput"
+	"github.com/mandelsoft/flagutils/progress"
+	"github.com/mandelsoft/flagutils/utils/out"
+)
+
+type source []int
+
+func (s source) Elements(output.ElementSpecs) (iter.Seq[int], error) {
+	return slices.Values(s), nil
+}
+
+var _ = Describe("progress", func() {
+	Context("reporter", func() {
+		var buf bytes.Buffer
+		var ctx context.Context
+
+		BeforeEach(func() {
+			buf.Reset()
+			ctx = out.With(context.Background(), out.New(nil, &buf))
+		})
+
+		It("reports the stages", func() {
+			r := progress.NewReporter(time.Millisecond, true)
+			r.Start(ctx)
+			r.Count(progress.STAGE_PRODUCED)
+			r.Count(progress.STAGE_PRODUCED)
+			r.Count(progress.STAGE_PROCESSED)
+			time.Sleep(20 * time.Millisecond)
+			r.Stop()
+			Expect(buf.String()).To(ContainSubstring(" produced 2, processed 1, elapsed "))
+			Expect(buf.String()).To(HaveSuffix("\r\033[K"))
+		})
+
+		It("omits reporting without terminal", func() {
+			r := progress.NewReporter(time.Millisecond, false)
+			r.Start(ctx)
+			r.Count(progress.STAGE_PRODUCED)
+			time.Sleep(10 * time.Millisecond)
+			r.Stop()
+			Expect(buf.String()).To(BeEmpty())
+			Expect(r.Get(progress.STAGE_PRODUCED)).To(Equal(1))
+		})
+
+		It("resets the counts on start", func() {
+			r := progress.NewReporter(time.Millisecond, false)
+			r.Count(progress.STAGE_PRODUCED)
+			r.Start(ctx)
+			r.Stop()
+			Expect(r.Get(progress.STAGE_PRODUCED)).To(Equal(0))
+		})
+
+		It("handles nil reporters", func() {
+			var r *progress.Reporter
+			r.Count(progress.STAGE_PRODUCED)
+			r.Start(ctx)
+			r.Stop()
+			Expect(r.Get(progress.STAGE_PRODUCED)).To(Equal(0))
+		})
+	})
+
+	Context("table output", func() {
+		var set flagutils.OptionSet
+
+		BeforeEach(func() {
+			f := tableoutput.NewOutputFactory[int](func(e int) output.Fields { return output.Fields{fmt.Sprintf("%d", e)} }, "VALUE")
+			set = flagutils.NewOptionSet(progress.New(), tableoutput.New(), output.New(output.NewOutputsFactory[int]().Add("", f)))
+		})
+
+		run := func(args ...string) *flagutilstest.Result {
+			return flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+				_, err := output.From[int](opts).GetOutput().Process(ctx, nil, source{1, 2, 3})
+				return err
+			}), args...)
+		}
+
+		It("counts the elements", func() {
+			r := run("--progress")
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal("VALUE\n1\n2\n3\n"))
+			Expect(r.Stderr).To(BeEmpty())
+			rep := progress.GetReporter(set)
+			Expect(rep.Get(progress.STAGE_PRODUCED)).To(Equal(3))
+			Expect(rep.Get(progress.STAGE_PROCESSED)).To(Equal(3))
+		})
+
+		It("counts the elements of every output separately", func() {
+			f := tableoutput.NewOutputFactory[int](func(e int) output.Fields { return output.Fields{fmt.Sprintf("%d", e)} }, "VALUE")
+			set = flagutils.NewOptionSet(progress.New(), tableoutput.New(), output.New(output.NewOutputsFactory[int]().Add("", f).Add("other", f)))
+			r := run("--progress", "-o", "other", "-o", "")
+			Expect(r.Error()).To(Succeed())
+			Expect(r.Stdout).To(Equal("VALUE\n1\n2\n3\nVALUE\n1\n2\n3\n"))
+			rep := progress.GetReporter(set)
+			Expect(rep.Get(progress.STAGE_PRODUCED)).To(Equal(3))
+			Expect(rep.Get(progress.STAGE_PROCESSED)).To(Equal(3))
+		})
+
+		It("is disabled by default", func() {
+			r := run()
+			Expect(r.Error()).To(Succeed())
+			Expect(progress.GetReporter(set)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,122 @@
+// Package prompt provides a flagutils.Prompter for terminals
+// used to enable the interactive mode of the lifecycle executors.
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+// Prompter requests flag values by writing a prompt to an output
+// stream and reading a single line from an input stream.
+// Secret values are read without echo, if the input is a terminal.
+type Prompter struct {
+	in     io.Reader
+	reader *bufio.Reader
+	out    io.Writer
+	cause  string
+}
+
+var _ flagutils.Prompter = (*Prompter)(nil)
+
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: in, reader: bufio.NewReader(in), out: out}
+}
+
+// IsTerminal reports whether the given stream is a terminal.
+func IsTerminal(s any) bool {
+	f, ok := s.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// ForTerminal provides a Prompter for standard input and
+// standard error output, if standard input is a terminal.
+// Otherwise, nil is returned.
+func ForTerminal() *Prompter {
+	if !IsTerminal(os.Stdin) {
+		return nil
+	}
+	return New(os.Stdin, os.Stderr)
+}
+
+// Interactive enables the interactive mode for the given context,
+// if standard input is a terminal.
+func Interactive(ctx context.Context) context.Context {
+	if p := ForTerminal(); p != nil {
+		return flagutils.WithPrompter(ctx, p)
+	}
+	return ctx
+}
+
+func (p *Prompter) Prompt(ctx context.Context, req *flagutils.PromptRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	f := req.Flag
+	if req.Cause != nil && req.Cause.Error() != p.cause {
+		p.cause = req.Cause.Error()
+		fmt.Fprintf(p.out, "%s\n", p.cause)
+	}
+	fmt.Fprintf(p.out, "--%s: %s\n", f.Name, f.Usage)
+	for i, c := range req.Choices {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, choice(c))
+	}
+	if req.Open && len(req.Choices) > 0 {
+		fmt.Fprintf(p.out, "  or any other value\n")
+	}
+
+	cur := ""
+	if !req.Secret && f.Value.String() != "" {
+		cur = fmt.Sprintf(" [%s]", f.Value.String())
+	}
+	for {
+		fmt.Fprintf(p.out, "%s%s: ", f.Name, cur)
+		line, err := p.read(req.Secret)
+		if err != nil {
+			return "", err
+		}
+		if line == "" || len(req.Choices) == 0 {
+			return line, nil
+		}
+		if slices.Contains(req.Choices, line) {
+			return line, nil
+		}
+		if n, err := strconv.Atoi(line); err == nil && n > 0 && n <= len(req.Choices) {
+			return req.Choices[n-1], nil
+		}
+		if req.Open {
+			return line, nil
+		}
+		fmt.Fprintf(p.out, "invalid choice %q\n", line)
+	}
+}
+
+func (p *Prompter) read(secret bool) (string, error) {
+	if secret && IsTerminal(p.in) {
+		data, err := term.ReadPassword(int(p.in.(*os.File).Fd()))
+		fmt.Fprintln(p.out)
+		return strings.TrimSpace(string(data)), err
+	}
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func choice(c string) string {
+	if c == "" {
+		return `""`
+	}
+	return c
+}
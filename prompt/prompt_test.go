@@ -0,0 +1,80 @@
+package prompt_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/prompt"
+)
+
+var _ = Describe("prompter", func() {
+	var fs *pflag.FlagSet
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.String("mode", "", "output mode")
+		fs.String("token", "secret", "access token")
+		out = &bytes.Buffer{}
+	})
+
+	request := func(name string, choices ...string) *flagutils.PromptRequest {
+		f := fs.Lookup(name)
+		return &flagutils.PromptRequest{Flag: f, Choices: choices, Secret: name == "token", Cause: fmt.Errorf("invalid %s", name)}
+	}
+
+	It("offers choices", func() {
+		p := prompt.New(strings.NewReader("1\n"), out)
+		Expect(Must(p.Prompt(context.Background(), request("mode", "", "yaml")))).To(Equal(""))
+		Expect(out.String()).To(Equal(`invalid mode
+--mode: output mode
+  1) ""
+  2) yaml
+mode: `))
+	})
+
+	It("accepts other values for open choices", func() {
+		p := prompt.New(strings.NewReader("xml\n2\n"), out)
+		req := request("mode", "json", "yaml")
+		Expect(Must(p.Prompt(context.Background(), req))).To(Equal("yaml"))
+		Expect(out.String()).To(HaveSuffix("  2) yaml\nmode: invalid choice \"xml\"\nmode: "))
+
+		out.Reset()
+		p = prompt.New(strings.NewReader("xml\n"), out)
+		req.Open = true
+		Expect(Must(p.Prompt(context.Background(), req))).To(Equal("xml"))
+		Expect(out.String()).To(HaveSuffix("  2) yaml\n  or any other value\nmode: "))
+	})
+
+	It("hides the value of secrets", func() {
+		p := prompt.New(strings.NewReader("other"), out)
+		Expect(Must(p.Prompt(context.Background(), request("token")))).To(Equal("other"))
+		Expect(out.String()).To(Equal("invalid token\n--token: access token\ntoken: "))
+	})
+
+	It("reports the cause only once", func() {
+		p := prompt.New(strings.NewReader("\n\n"), out)
+		Expect(Must(p.Prompt(context.Background(), request("mode")))).To(Equal(""))
+		Expect(Must(p.Prompt(context.Background(), request("mode")))).To(Equal(""))
+		Expect(strings.Count(out.String(), "invalid mode")).To(Equal(1))
+	})
+
+	It("reports end of input", func() {
+		p := prompt.New(strings.NewReader(""), out)
+		_, err := p.Prompt(context.Background(), request("mode"))
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("detects non-terminals", func() {
+		Expect(prompt.IsTerminal(out)).To(BeFalse())
+	})
+})
@@ -0,0 +1,256 @@
+package flagutils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/spf13/pflag"
+)
+
+// FlagOriginAnnotation is the pflag.Flag annotation used to
+// record the origin of a flag value.
+const FlagOriginAnnotation = "flagutils-origin"
+
+// OriginKind describes the kind of source a flag value is taken from.
+type OriginKind string
+
+const (
+	ORIGIN_DEFAULT      OriginKind = "default"
+	ORIGIN_COMMANDLINE  OriginKind = "command line"
+	ORIGIN_ENVIRONMENT  OriginKind = "environment"
+	ORIGIN_CONFIG       OriginKind = "config file"
+	ORIGIN_PRESET       OriginKind = "preset"
+	ORIGIN_PROGRAMMATIC OriginKind = "programmatic"
+	ORIGIN_INTERACTIVE  OriginKind = "interactive input"
+)
+
+// Origin describes the provenance of a flag value.
+// The optional Source describes the concrete source,
+// for example, the name of an environment variable or a file path.
+type Origin struct {
+	Kind   OriginKind
+	Source string
+}
+
+func NewOrigin(kind OriginKind, source ...string) Origin {
+	return Origin{Kind: kind, Source: strings.Join(source, "")}
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return string(o.Kind)
+	}
+	return fmt.Sprintf("%s %s", o.Kind, o.Source)
+}
+
+// SetOrigin records the origin for the actual value of a flag.
+func SetOrigin(f *pflag.Flag, origin Origin) {
+	if f.Annotations == nil {
+		f.Annotations = map[string][]string{}
+	}
+	f.Annotations[FlagOriginAnnotation] = []string{string(origin.Kind), origin.Source}
+}
+
+// FlagOrigin provides the origin of the actual value of a flag.
+// Changed flags without recorded origin are assumed to be
+// set by the command line.
+func FlagOrigin(f *pflag.Flag) Origin {
+	if f.Annotations != nil {
+		if list := f.Annotations[FlagOriginAnnotation]; len(list) > 0 {
+			return NewOrigin(OriginKind(list[0]), list[1:]...)
+		}
+	}
+	if f.Changed {
+		return NewOrigin(ORIGIN_COMMANDLINE)
+	}
+	return NewOrigin(ORIGIN_DEFAULT)
+}
+
+// SetFlag sets the value of a flag and records its origin.
+// For an alias flag, the origin is recorded for the original flag, also.
+func SetFlag(fs *pflag.FlagSet, name, value string, origin Origin) error {
+	if err := fs.Set(name, value); err != nil {
+		return err
+	}
+	f := fs.Lookup(name)
+	SetOrigin(f, origin)
+	if t := FlagAliasTarget(f); t != nil {
+		SetOrigin(t, origin)
+	}
+	return nil
+}
+
+// ParseCompleter is an optional interface for flag values, which
+// complete their evaluation after all command line arguments are
+// parsed by Parse, for example, to set other flags not given explicitly.
+// Parse calls BeginParse before the arguments are parsed and
+// CompleteParse afterward. Without Parse (for example, for a
+// plain pflag.FlagSet.Parse) none of the methods is called.
+type ParseCompleter interface {
+	BeginParse(fs *pflag.FlagSet)
+	CompleteParse(fs *pflag.FlagSet) error
+}
+
+// Parse parses the command line arguments for a flag set
+// like pflag.FlagSet.Parse, but records the origin of
+// the given flag values. Afterward, flag values implementing
+// ParseCompleter are completed.
+func Parse(fs *pflag.FlagSet, args []string) error {
+	fs.VisitAll(func(f *pflag.Flag) {
+		if c, ok := f.Value.(ParseCompleter); ok {
+			c.BeginParse(fs)
+		}
+	})
+	err := fs.ParseAll(args, func(f *pflag.Flag, value string) error {
+		return SetFlag(fs, f.Name, value, NewOrigin(ORIGIN_COMMANDLINE))
+	})
+	fs.VisitAll(func(f *pflag.Flag) {
+		if c, ok := f.Value.(ParseCompleter); ok {
+			if cerr := c.CompleteParse(fs); err == nil {
+				err = cerr
+			}
+		}
+	})
+	return err
+}
+
+// EnvironmentName provides the name of the environment variable
+// used by ApplyEnvironment for a flag.
+func EnvironmentName(prefix, name string) string {
+	n := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if prefix == "" {
+		return n
+	}
+	return strings.ToUpper(prefix) + "_" + n
+}
+
+// ApplyEnvironment sets all flags not yet changed, for which
+// an environment variable <PREFIX>_<FLAG_NAME> is defined.
+func ApplyEnvironment(fs *pflag.FlagSet, prefix string) error {
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		env := EnvironmentName(prefix, f.Name)
+		if v, ok := os.LookupEnv(env); ok {
+			err = errors.Wrapf(SetFlag(fs, f.Name, v, NewOrigin(ORIGIN_ENVIRONMENT, env)), "environment variable %s", env)
+		}
+	})
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// FlagSetRecorder is an Options object recording the pflag.FlagSet
+// its OptionSet is added to. It is used to access flag related information
+// based on an OptionSet, for example, by Provenance.
+// If an OptionSet does not contain one, ExecuteLifecycle uses a local
+// FlagSetRecorder for the lifecycle without modifying the OptionSet.
+type FlagSetRecorder struct {
+	fs *pflag.FlagSet
+}
+
+var (
+	_ Options       = (*FlagSetRecorder)(nil)
+	_ FlagSetBinder = (*FlagSetRecorder)(nil)
+)
+
+func NewFlagSetRecorder() *FlagSetRecorder {
+	return &FlagSetRecorder{}
+}
+
+func (r *FlagSetRecorder) AddFlags(fs *pflag.FlagSet) {
+	r.fs = fs
+}
+
+func (r *FlagSetRecorder) BindFlagSet(fs *pflag.FlagSet) {
+	r.fs = fs
+}
+
+func (r *FlagSetRecorder) FlagSet() *pflag.FlagSet {
+	return r.fs
+}
+
+// GetFlagSet provides the pflag.FlagSet recorded by a FlagSetRecorder
+// in the given OptionSet. If there is none, nil is returned.
+func GetFlagSet(opts OptionSetProvider) *pflag.FlagSet {
+	r := GetFrom[*FlagSetRecorder](opts)
+	if r == nil {
+		return nil
+	}
+	return r.fs
+}
+
+// Provenance provides the origin of the value of the flag with the
+// given name. The OptionSet must contain a FlagSetRecorder.
+func Provenance(opts OptionSetProvider, name string) Origin {
+	fs := GetFlagSet(opts)
+	if fs == nil {
+		return Origin{}
+	}
+	f := fs.Lookup(name)
+	if f == nil {
+		return Origin{}
+	}
+	return FlagOrigin(f)
+}
+
+// ProvenanceUsages describes the effective values of all changed
+// flags of a flag set together with their origin.
+func ProvenanceUsages(fs *pflag.FlagSet) string {
+	var lines []string
+	fs.VisitAll(func(f *pflag.Flag) {
+		o := FlagOrigin(f)
+		if o.Kind != ORIGIN_DEFAULT {
+			lines = append(lines, fmt.Sprintf("  --%s=%s (%s)\n", f.Name, f.Value.String(), o))
+		}
+	})
+	return strings.Join(lines, "")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ProvenanceError is a validation error of an Options object
+// enriched by the origins of the changed flags of this object.
+type ProvenanceError struct {
+	err     error
+	origins []string
+}
+
+func (e *ProvenanceError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.err, strings.Join(e.origins, ", "))
+}
+
+func (e *ProvenanceError) Unwrap() error {
+	return e.err
+}
+
+// withProvenance enriches a validation error for an Options object by the
+// origins of its flags, if the OptionSet provides a recorded flag set.
+func withProvenance(opts OptionSet, o any, err error) error {
+	var perr *ProvenanceError
+	if err == nil || errors.As(err, &perr) {
+		return err
+	}
+	opt, ok := o.(Options)
+	if !ok {
+		return err
+	}
+	fs := GetFlagSet(opts)
+	if fs == nil {
+		return err
+	}
+	var origins []string
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed && IsFlagOf(f, opt) {
+			origins = append(origins, fmt.Sprintf("--%s from %s", f.Name, FlagOrigin(f)))
+		}
+	})
+	if len(origins) == 0 {
+		return err
+	}
+	return &ProvenanceError{err, origins}
+}
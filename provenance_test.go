@@ -0,0 +1,105 @@
+package flagutils_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/utils/out"
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("provenance", func() {
+	var set flagutils.ExtendableOptionSet
+	var fs *pflag.FlagSet
+	var popt *parallel.Options
+
+	BeforeEach(func() {
+		popt = parallel.New()
+		set = flagutils.NewOptionSet(flagutils.NewFlagSetRecorder(), popt, &TestOption{})
+		fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		set.AddFlags(fs)
+	})
+
+	It("reports default", func() {
+		MustBeSuccessful(flagutils.Parse(fs, nil))
+		Expect(flagutils.Provenance(set, "parallel")).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_DEFAULT)))
+	})
+
+	It("reports command line", func() {
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "2"}))
+		Expect(flagutils.Provenance(set, "parallel")).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+	})
+
+	It("reports environment", func() {
+		os.Setenv("FLAGTEST_PARALLEL", "3")
+		defer os.Unsetenv("FLAGTEST_PARALLEL")
+		os.Setenv("FLAGTEST_TEST", "true")
+		defer os.Unsetenv("FLAGTEST_TEST")
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-t=false"}))
+		MustBeSuccessful(flagutils.ApplyEnvironment(fs, "flagtest"))
+		Expect(popt.Value()).To(Equal(3))
+		Expect(flagutils.Provenance(set, "parallel")).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_ENVIRONMENT, "FLAGTEST_PARALLEL")))
+		Expect(flagutils.Provenance(set, "test")).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+	})
+
+	It("reports programmatic set", func() {
+		popt.Set(4)
+		Expect(flagutils.Provenance(set, "parallel").String()).To(Equal("programmatic"))
+		Expect(flagutils.ProvenanceUsages(fs)).To(Equal("  --parallel=4 (programmatic)\n"))
+	})
+
+	It("reports programmatic set before adding the flags", func() {
+		p := parallel.New().WithNames("workers", "w").Set(5)
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		set := flagutils.NewOptionSet(flagutils.NewFlagSetRecorder(), p)
+		set.AddFlags(fs)
+		Expect(flagutils.Provenance(set, "workers")).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_PROGRAMMATIC)))
+	})
+
+	It("enriches validation errors", func() {
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "-1"}))
+		Expect(flagutils.Validate(context.Background(), set, nil)).To(MatchError("invalid degree of parallelism: -1 (--parallel from command line)"))
+	})
+
+	It("distinguishes instances of the same type", func() {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		set := flagutils.NewOptionSet(flagutils.NewFlagSetRecorder(), parallel.New(), parallel.New().WithNames("workers", "w"))
+		set.AddFlags(fs)
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "-1", "-w", "2"}))
+		Expect(flagutils.Validate(context.Background(), set, nil)).To(MatchError("invalid degree of parallelism: -1 (--parallel from command line)"))
+
+		MustBeSuccessful(flagutils.Parse(fs, []string{"-p", "1", "-w", "-2"}))
+		Expect(flagutils.Validate(context.Background(), set, nil)).To(MatchError("invalid degree of parallelism: -2 (--workers from command line)"))
+	})
+
+	Context("lifecycle", func() {
+		BeforeEach(func() {
+			set = flagutils.NewOptionSet(popt)
+		})
+
+		It("uses a local flag set recorder", func() {
+			var origin flagutils.Origin
+			MustBeSuccessful(flagutils.ExecuteLifecycle(context.Background(), "test", set, RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+				origin = flagutils.Provenance(opts, "parallel")
+				return nil
+			}), "-p", "2"))
+			Expect(origin).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+			Expect(flagutils.Filter[*flagutils.FlagSetRecorder](set)).To(BeEmpty())
+		})
+
+		It("describes effective values in the usage", func() {
+			var buf bytes.Buffer
+			ctx := out.With(context.Background(), out.New(nil, &buf))
+			Expect(flagutils.ExecuteLifecycle(ctx, "test", set, nil, "-p", "2", "--help")).To(MatchError(pflag.ErrHelp))
+			Expect(buf.String()).To(HavePrefix("Usage of test:\n"))
+			Expect(buf.String()).To(HaveSuffix("\nEffective values:\n  --parallel=2 (command line)\n"))
+		})
+	})
+})
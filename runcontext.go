@@ -0,0 +1,61 @@
+package flagutils
+
+import (
+	"context"
+)
+
+// ContextProvider is an optional interface for Options objects.
+// It is used to provide the context for the run phase after
+// a successful validation, for example, to install an output
+// destination (see RunContext).
+type ContextProvider interface {
+	ProvideContext(ctx context.Context, opts OptionSet) (context.Context, error)
+}
+
+// RunContext provides the context for the run phase of an OptionSet.
+// All ContextProvider objects of the set are applied in the
+// order of the set.
+// The lifecycle executors (ExecuteLifecycle and LifecycleRunner) call
+// it after a successful validation. An error is reported as failure
+// of the run phase.
+func RunContext(ctx context.Context, opts OptionSetProvider) (context.Context, error) {
+	var err error
+
+	set := opts.AsOptionSet()
+	for _, p := range Filter[ContextProvider](set) {
+		ctx, err = p.ProvideContext(ctx, set)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// runWithContext runs a Runner with the context provided by RunContext.
+func runWithContext(ctx context.Context, opts OptionSet, run Runner) error {
+	ctx, err := RunContext(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return run.Run(ctx, opts)
+}
+
+type failureKey struct{}
+
+// WithFailure provides a context for the finalization
+// describing the failure of a previous lifecycle phase.
+// It is used by the lifecycle executors to enable Finalizable
+// objects to roll back their work.
+func WithFailure(ctx context.Context, err error) context.Context {
+	if err == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, failureKey{}, err)
+}
+
+// GetFailure provides the failure of a previous lifecycle phase
+// during the finalization (see WithFailure).
+func GetFailure(ctx context.Context) error {
+	err, _ := ctx.Value(failureKey{}).(error)
+	return err
+}
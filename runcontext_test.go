@@ -0,0 +1,61 @@
+package flagutils_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+)
+
+type contextOption struct {
+	err     error
+	failure error
+}
+
+func (o *contextOption) AddFlags(fs *pflag.FlagSet) {}
+
+func (o *contextOption) ProvideContext(ctx context.Context, opts flagutils.OptionSet) (context.Context, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	return context.WithValue(ctx, "provided", "value"), nil
+}
+
+func (o *contextOption) Finalize(ctx context.Context, opts flagutils.OptionSet, v flagutils.FinalizationSet) error {
+	o.failure = flagutils.GetFailure(ctx)
+	return nil
+}
+
+var _ = Describe("run context", func() {
+	It("provides the run context", func() {
+		o := &contextOption{}
+		var value any
+		MustBeSuccessful(flagutils.ExecuteLifecycle(context.Background(), "test", flagutils.NewOptionSet(o), RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			value = ctx.Value("provided")
+			return nil
+		})))
+		Expect(value).To(Equal("value"))
+		Expect(o.failure).To(BeNil())
+	})
+
+	It("passes the failure to the finalization", func() {
+		o := &contextOption{}
+		Expect(flagutils.ExecuteLifecycle(context.Background(), "test", flagutils.NewOptionSet(o), RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			return fmt.Errorf("failed")
+		}))).To(MatchError("run failed: failed"))
+		Expect(o.failure).To(MatchError("run failed: failed"))
+	})
+
+	It("reports context errors", func() {
+		o := &contextOption{err: fmt.Errorf("no context")}
+		Expect(flagutils.NewLifecycleRunner("test").Execute(context.Background(), flagutils.NewOptionSet(o), RunFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			return nil
+		}))).To(MatchError("run failed: no context"))
+		Expect(o.failure).To(MatchError("run failed: no context"))
+	})
+})
@@ -0,0 +1,100 @@
+// Package secret provides an Options type for secrets like
+// tokens or passwords, which should not be passed literally on
+// the command line.
+package secret
+
+import (
+	"context"
+	"io"
+
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/pflags"
+)
+
+// SOURCES_HELP describes the possible sources of a secret value.
+const SOURCES_HELP = "(use @<file>, env:<variable> or - for stdin)"
+
+// Options describes a secret flag. The value may be given by
+// a file (@<path>), an environment variable (env:<name>),
+// standard input (-) or literally. The value is never shown
+// in help, dumps or String(). Finalize zeroes a value read by the
+// flag. Values provided by Set belong to the caller and are only reset.
+type Options struct {
+	flagutils.SimpleOption[[]byte, *Options]
+	stdin io.Reader
+	ref   *[]byte
+	value pflag.Value
+}
+
+var (
+	_ flagutils.Options     = (*Options)(nil)
+	_ flagutils.Finalizable = (*Options)(nil)
+)
+
+// From provides the first secret Options object of an OptionSet.
+// Use GetFor to retrieve the object for a dedicated flag.
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+// GetFor provides the secret Options object for the given flag name.
+func GetFor(opts flagutils.OptionSetProvider, name string) *Options {
+	for _, o := range flagutils.Filter[*Options](opts) {
+		if n, _ := o.GetNames(); n == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// New creates a secret option with the given flag name.
+func New(name, desc string) *Options {
+	o := &Options{}
+	o.SimpleOption = flagutils.NewSimpleOptionWithSetter[[]byte](o, o.varP, nil, name, "", desc+" "+SOURCES_HELP)
+	return o
+}
+
+// WithStdin sets the reader used for the value -.
+// By default, os.Stdin is used.
+func (o *Options) WithStdin(r io.Reader) *Options {
+	o.stdin = r
+	return o
+}
+
+func (o *Options) varP(fs *pflag.FlagSet, p *[]byte, name, shorthand string, value []byte, usage string) {
+	o.ref = p
+	o.value = pflags.SecretInputVarPF(fs, p, o.stdin, name, shorthand, value, usage).Value
+}
+
+// IsSet reports whether a non-empty secret is given.
+func (o *Options) IsSet() bool {
+	return len(o.Value()) > 0
+}
+
+// String never provides the secret value.
+func (o *Options) String() string {
+	if o.IsSet() {
+		return pflags.SecretMask
+	}
+	return ""
+}
+
+// Clear resets the value. The memory used for the secret is zeroed,
+// if the secret has been read by the flag.
+func (o *Options) Clear() {
+	if o.value != nil {
+		pflags.ClearSecret(o.value)
+	}
+	if o.ref != nil {
+		*o.ref = nil
+	} else {
+		o.SimpleOption.Set(nil)
+	}
+}
+
+func (o *Options) Finalize(ctx context.Context, opts flagutils.OptionSet, v flagutils.FinalizationSet) error {
+	o.Clear()
+	return nil
+}
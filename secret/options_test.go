@@ -0,0 +1,78 @@
+package secret_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/dump"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/secret"
+)
+
+var _ = Describe("secret options", func() {
+	var token *secret.Options
+
+	BeforeEach(func() {
+		token = secret.New("token", "access token").WithStdin(strings.NewReader("s3cr3t\n"))
+	})
+
+	It("provides the secret and clears it on finalization", func() {
+		var value string
+		var mem []byte
+		set := flagutils.NewOptionSet(token)
+		r := flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			o := secret.GetFor(opts, "token")
+			value = string(o.Value())
+			mem = o.Value()
+			Expect(o.String()).To(Equal("***"))
+			return nil
+		}), "--token", "-")
+		Expect(r.Error()).To(Succeed())
+		Expect(value).To(Equal("s3cr3t"))
+		Expect(mem).To(Equal(make([]byte, 6)))
+		Expect(token.IsSet()).To(BeFalse())
+	})
+
+	It("keeps a value provided by the caller", func() {
+		buf := []byte("s3cr3t")
+		token.Set(buf)
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(token), nil)
+		Expect(r.Error()).To(Succeed())
+		Expect(token.IsSet()).To(BeFalse())
+		Expect(string(buf)).To(Equal("s3cr3t"))
+	})
+
+	It("keeps a value provided by the caller after parsing", func() {
+		var mem []byte
+		buf := []byte("other")
+		r := flagutilstest.Run(context.Background(), flagutils.NewOptionSet(token), flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			mem = token.Value()
+			Expect(string(mem)).To(Equal("s3cr3t"))
+			token.Set(buf)
+			return nil
+		}), "--token", "-")
+		Expect(r.Error()).To(Succeed())
+		Expect(string(buf)).To(Equal("other"))
+		Expect(mem).To(Equal(make([]byte, 6)))
+	})
+
+	It("is not shown in help and dumps", func() {
+		set := flagutils.NewOptionSet(token)
+		r := flagutilstest.Run(context.Background(), set, flagutilstest.RunnerFunc(func(ctx context.Context, opts flagutils.OptionSet) error {
+			fs := flagutils.GetFlagSet(opts)
+			Expect(fs.FlagUsages()).To(Equal("      --token secret   access token (use @<file>, env:<variable> or - for stdin)\n"))
+			Expect(flagutils.ProvenanceUsages(fs)).To(Equal("  --token=*** (command line)\n"))
+
+			doc := dump.Collect(opts, fs)
+			Expect(doc.Flags).To(HaveLen(1))
+			Expect(doc.Flags[0].Value).To(BeNil())
+			return nil
+		}), "--token", "s3cr3t")
+		Expect(r.Error()).To(Succeed())
+		Expect(flagutils.FlagOrigin(r.FlagSet.Lookup("token"))).To(Equal(flagutils.NewOrigin(flagutils.ORIGIN_COMMANDLINE)))
+	})
+})
@@ -13,6 +13,10 @@ type SimpleOption[V any, T Options] struct {
 	long   string
 	short  string
 	desc   string
+	attrs  FlagAttributes
+	flag   *pflag.Flag
+	// set records a programmatic value.
+	set bool
 }
 
 func NewSimpleOption[V any, T Options](self T, def V, long, short, desc string) SimpleOption[V, T] {
@@ -25,17 +29,33 @@ func NewSimpleOptionWithSetter[V any, T Options](self T, setter VarPFunc[V], def
 
 func (o *SimpleOption[V, T]) AddFlags(fs *pflag.FlagSet) {
 	o.setter(fs, &o.value, o.long, o.short, o.value, o.desc)
+	ApplyFlagAttributes(fs, o.long, o.attrs)
+	o.flag = fs.Lookup(o.long)
+	if o.set && o.flag != nil {
+		SetOrigin(o.flag, NewOrigin(ORIGIN_PROGRAMMATIC))
+	}
 }
 
 func (o *SimpleOption[V, T]) Value() V {
 	return o.value
 }
 
+// Set sets the option value. The origin of the value is recorded
+// as programmatic, regardless of whether the flag is already added
+// to a pflag.FlagSet.
 func (o *SimpleOption[V, T]) Set(v V) T {
 	o.value = v
+	o.set = true
+	if o.flag != nil {
+		SetOrigin(o.flag, NewOrigin(ORIGIN_PROGRAMMATIC))
+	}
 	return o.self
 }
 
+func (o *SimpleOption[V, T]) GetNames() (string, string) {
+	return o.long, o.short
+}
+
 func (o *SimpleOption[V, T]) WithNames(l, s string) T {
 	o.long = l
 	o.short = s
@@ -47,6 +67,55 @@ func (o *SimpleOption[V, T]) WithDescription(s string) T {
 	return o.self
 }
 
+// WithAliases adds old names for the flag, which are still accepted,
+// but print a deprecation message on use.
+func (o *SimpleOption[V, T]) WithAliases(names ...string) T {
+	o.attrs.Aliases = append(o.attrs.Aliases, names...)
+	return o.self
+}
+
+// WithDeprecation marks the flag as deprecated.
+// The message is printed on use and shown in help.
+func (o *SimpleOption[V, T]) WithDeprecation(msg string) T {
+	o.attrs.Deprecated = msg
+	return o.self
+}
+
+// WithShorthandDeprecation marks the shorthand of the flag as deprecated.
+func (o *SimpleOption[V, T]) WithShorthandDeprecation(msg string) T {
+	o.attrs.ShorthandDeprecated = msg
+	return o.self
+}
+
+func (o *SimpleOption[V, T]) WithHidden() T {
+	o.attrs.Hidden = true
+	return o.self
+}
+
+// WithRequired marks the flag as required.
+// It must be given on the command line or by interactive input.
+func (o *SimpleOption[V, T]) WithRequired() T {
+	o.attrs.Required = true
+	return o.self
+}
+
+// WithChoices declares the possible values offered by interactive input.
+func (o *SimpleOption[V, T]) WithChoices(choices ...string) T {
+	o.attrs.Choices = choices
+	return o.self
+}
+
+func (o *SimpleOption[V, T]) GetFlagAttributes() FlagAttributes {
+	return o.attrs
+}
+
+func (o *SimpleOption[V, T]) GetFlagAliases() map[string][]string {
+	if len(o.attrs.Aliases) == 0 {
+		return nil
+	}
+	return map[string][]string{o.long: o.attrs.Aliases}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func VarPFuncFor[T any]() VarPFunc[T] {
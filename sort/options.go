@@ -41,6 +41,16 @@ func (o *Options) WithComparator(name string, cmp general.CompareFunc[string]) *
 	return o
 }
 
+// WithNumericFields declares fields with numeric values. Their raw
+// values are compared by output.CompareRaw, which compares numbers
+// numerically.
+func (o *Options) WithNumericFields(names ...string) *Options {
+	for _, n := range names {
+		o.WithComparator(n, output.CompareRaw)
+	}
+	return o
+}
+
 func (o *Options) GetComparator(name string) general.CompareFunc[string] {
 	return o.comparators[name]
 }
@@ -51,7 +61,11 @@ type fieldInfo struct {
 	cmp   general.CompareFunc[string]
 }
 
+// Validate determines the sort fields. It may be called several
+// times, for example, by flagutils.ValidateInteractively, so
+// the field information is always determined from scratch.
 func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flagutils.ValidationSet) error {
+	o.fieldInfos = nil
 	sortFields := o.Value()
 	if len(sortFields) == 0 {
 		return nil
@@ -77,6 +91,7 @@ func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flag
 	}
 
 	var wrong []string
+	var infos []*fieldInfo
 	for _, v := range sortFields {
 		order := 1
 		if strings.HasPrefix(v, "-") {
@@ -87,27 +102,32 @@ func (o *Options) Validate(ctx context.Context, opts flagutils.OptionSet, v flag
 		if idx < 0 {
 			wrong = append(wrong, v)
 		}
-		cmp := o.comparators[v]
-		if cmp == nil {
-			cmp = strings.Compare
-		}
-		info := &fieldInfo{order: order, index: idx, cmp: cmp}
-		o.fieldInfos = append(o.fieldInfos, info)
+		info := &fieldInfo{order: order, index: idx, cmp: o.comparators[v]}
+		infos = append(infos, info)
 	}
 
 	if len(wrong) != 0 {
 		sort.Strings(wrong)
 		return fmt.Errorf("invalid sort fields: %v", wrong)
 	}
-	slices.Reverse(o.fieldInfos)
+	slices.Reverse(infos)
+	o.fieldInfos = infos
 	return nil
 }
 
+// Compare compares the raw fields (see output.RawFieldProvider).
+// Without a configured comparator, the values are compared
+// lexically. Fields declared as numeric (see WithNumericFields)
+// are compared numerically.
 func (o *Options) Compare(af, bf output.FieldProvider) int {
-	a := af.GetFields()
-	b := bf.GetFields()
+	a := output.GetRawFields(af)
+	b := output.GetRawFields(bf)
 	for _, i := range o.fieldInfos {
-		if c := i.cmp(a[i.index], b[i.index]); c != 0 {
+		cmp := i.cmp
+		if cmp == nil {
+			cmp = strings.Compare
+		}
+		if c := cmp(a[i.index], b[i.index]); c != 0 {
 			return c * i.order
 		}
 	}
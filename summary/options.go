@@ -0,0 +1,33 @@
+package summary
+
+import (
+	"github.com/mandelsoft/flagutils"
+)
+
+// Options is the --summary option requesting a summary
+// (element count, totals and averages) from the output modes
+// supporting it (table, tree and manifest output).
+type Options struct {
+	flagutils.SimpleOption[bool, *Options]
+}
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+var (
+	_ flagutils.Options = (*Options)(nil)
+)
+
+func New() *Options {
+	o := &Options{}
+	o.SimpleOption = flagutils.NewSimpleOption(o, false, "summary", "", "show summary")
+	return o
+}
+
+// Requested reports whether a summary is requested
+// by an option set.
+func Requested(opts flagutils.OptionSetProvider) bool {
+	o := From(opts)
+	return o != nil && o.Value()
+}
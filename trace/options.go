@@ -0,0 +1,140 @@
+// Package trace provides an option tracing the option lifecycle.
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/utils/history"
+	"github.com/mandelsoft/flagutils/utils/out"
+	"github.com/mandelsoft/flagutils/utils/tree"
+)
+
+// Options is a flagutils.LifecycleObserver printing a tree of
+// the lifecycle traversal of its OptionSet on the error output of
+// the output context, if the trace flag is given.
+// Because the preparation is done before the flags are parsed, its
+// events are recorded and printed together with the validation.
+type Options struct {
+	flagutils.SimpleOption[bool, *Options]
+
+	lock    sync.Mutex
+	stack   []*node
+	pending []*node
+}
+
+var (
+	_ flagutils.Options           = (*Options)(nil)
+	_ flagutils.LifecycleObserver = (*Options)(nil)
+)
+
+func From(opts flagutils.OptionSetProvider) *Options {
+	return flagutils.GetFrom[*Options](opts)
+}
+
+func New() *Options {
+	o := &Options{}
+	o.SimpleOption = flagutils.NewSimpleOption[bool](o, false, "trace-options", "", "trace option lifecycle on stderr")
+	return o
+}
+
+type node struct {
+	event    *flagutils.LifecycleEvent
+	children []*node
+}
+
+func (o *Options) LifecycleEvent(ctx context.Context, e *flagutils.LifecycleEvent) {
+	if e.Phase == flagutils.PHASE_PARSE {
+		// parsing does not involve Options objects.
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	switch e.Kind {
+	case flagutils.EVENT_BEFORE:
+		n := &node{event: e}
+		if len(o.stack) > 0 {
+			p := o.stack[len(o.stack)-1]
+			p.children = append(p.children, n)
+		}
+		o.stack = append(o.stack, n)
+	case flagutils.EVENT_AFTER:
+		if len(o.stack) == 0 {
+			return
+		}
+		n := o.stack[len(o.stack)-1]
+		o.stack = o.stack[:len(o.stack)-1]
+		n.event = e
+		if len(o.stack) > 0 {
+			return
+		}
+		o.pending = append(o.pending, n)
+		if o.Value() {
+			var buf strings.Builder
+			for _, p := range o.pending {
+				format(&buf, p)
+			}
+			out.ErrPrint(ctx, buf.String())
+			o.pending = nil
+		} else if e.Phase != flagutils.PHASE_PREPARE {
+			o.pending = nil
+		}
+	}
+}
+
+// element is a traced event with the ids of the enclosing
+// events used to map the events to a tree (see tree.MapToTree).
+type element struct {
+	history.History[int]
+	id   int
+	node *node
+}
+
+var _ tree.Object[int] = (*element)(nil)
+
+func (e *element) GetHistory() history.History[int] {
+	return e.History
+}
+
+func (e *element) IsNode() *int {
+	if len(e.node.children) == 0 {
+		return nil
+	}
+	return &e.id
+}
+
+func format(buf *strings.Builder, n *node) {
+	fmt.Fprintf(buf, "%s\n", describe(n.event))
+	var objs tree.Objects[int]
+	elements(&objs, n.children, nil)
+	for _, t := range tree.MapToTree(objs, nil, "") {
+		fmt.Fprintf(buf, "%s %s\n", t.Graph, describe(t.Object.(*element).node.event))
+	}
+}
+
+// elements adds the elements for the given nodes and
+// their children in depth-first order.
+func elements(objs *tree.Objects[int], nodes []*node, h history.History[int]) {
+	for _, n := range nodes {
+		e := &element{History: h, id: len(*objs), node: n}
+		*objs = append(*objs, e)
+		elements(objs, n.children, h.Add(e.id))
+	}
+}
+
+func describe(e *flagutils.LifecycleEvent) string {
+	desc := e.Description
+	if e.Object == nil {
+		desc = string(e.Phase)
+	}
+	desc = fmt.Sprintf("%s (%s)", desc, e.Duration.Round(time.Microsecond))
+	if e.Error != nil {
+		desc += fmt.Sprintf(": %s", e.Error)
+	}
+	return desc
+}
@@ -0,0 +1,74 @@
+package trace_test
+
+import (
+	"context"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/flagutilstest"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/preset"
+	"github.com/mandelsoft/flagutils/trace"
+)
+
+var durations = regexp.MustCompile(`\([^)]*s\)`)
+
+// Nested prepares the given Options objects during its preparation.
+type Nested struct {
+	flagutils.NoOptions
+	nested []flagutils.Options
+}
+
+func (o *Nested) Prepare(ctx context.Context, opts flagutils.OptionSet, v flagutils.PreparationSet) error {
+	for _, n := range o.nested {
+		if err := v.Prepare(ctx, opts, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ = Describe("trace options", func() {
+	var set flagutils.ExtendableOptionSet
+
+	BeforeEach(func() {
+		set = flagutils.NewOptionSet(trace.New(), preset.New(), parallel.New())
+	})
+
+	It("prints the lifecycle tree", func() {
+		r := flagutilstest.Run(context.Background(), set, nil, "--trace-options", "-p", "-1")
+		Expect(r.ValidationError).To(MatchError("invalid degree of parallelism: -1 (--parallel from command line)"))
+		Expect(durations.ReplaceAllString(r.Stderr, "(d)")).To(Equal(`preparation (d)
+└─ *preset.Options (d)
+validation (d): invalid degree of parallelism: -1 (--parallel from command line)
+└─ *parallel.Options (d): invalid degree of parallelism: -1
+finalization (d)
+└─ *parallel.Options (d)
+`))
+	})
+
+	It("prints nested preparations", func() {
+		leaf := &Nested{}
+		inner := &Nested{nested: []flagutils.Options{leaf}}
+		set = flagutils.NewOptionSet(trace.New(), &Nested{nested: []flagutils.Options{inner, &Nested{}}}, preset.New())
+		r := flagutilstest.Run(context.Background(), set, nil, "--trace-options")
+		Expect(r.Error()).To(Succeed())
+		Expect(durations.ReplaceAllString(r.Stderr, "(d)")).To(HavePrefix(`preparation (d)
+├─ *trace_test.Nested (d)
+│  ├─ *trace_test.Nested (d)
+│  │  └─ *trace_test.Nested (d)
+│  └─ *trace_test.Nested (d)
+└─ *preset.Options (d)
+validation (d)
+`))
+	})
+
+	It("is silent by default", func() {
+		r := flagutilstest.Run(context.Background(), set, nil)
+		Expect(r.Error()).To(Succeed())
+		Expect(r.Stderr).To(Equal(""))
+	})
+})
@@ -0,0 +1,167 @@
+package flagutils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils/pflags"
+)
+
+// FlagValueGetter provides the typed value of a flag of a pflag.FlagSet.
+type FlagValueGetter[T any] func(fs *pflag.FlagSet, name string) (T, error)
+
+type valueGetter func(fs *pflag.FlagSet, name string) (any, error)
+
+var (
+	gettersLock sync.RWMutex
+	getters     = map[reflect.Type]map[string]valueGetter{}
+)
+
+// RegisterFlagValueType registers a getter for values of type T
+// provided by flags with the given pflag type name (pflag.Value.Type()).
+// It is used by Value and FlagValue.
+func RegisterFlagValueType[T any](ftype string, get FlagValueGetter[T]) {
+	gettersLock.Lock()
+	defer gettersLock.Unlock()
+
+	t := generics.TypeOf[T]()
+	m := getters[t]
+	if m == nil {
+		m = map[string]valueGetter{}
+		getters[t] = m
+	}
+	m[ftype] = func(fs *pflag.FlagSet, name string) (any, error) { return get(fs, name) }
+}
+
+func getter(t reflect.Type, ftype string) valueGetter {
+	gettersLock.RLock()
+	defer gettersLock.RUnlock()
+	return getters[t][ftype]
+}
+
+// Value provides the typed value of the flag with the given name
+// from the pflag.FlagSet recorded for an OptionSet (see FlagSetRecorder).
+// This way, a flag value can be accessed without knowing the Options
+// type responsible for the flag, for example, for logging or auditing.
+func Value[T any](opts OptionSetProvider, name string) (T, error) {
+	var _nil T
+	fs := GetFlagSet(opts)
+	if fs == nil {
+		return _nil, fmt.Errorf("no flag set recorded for option set")
+	}
+	return FlagValue[T](fs, name)
+}
+
+// FlagValue provides the typed value of the flag with the given name.
+// Alias flags provide the value of the original flag.
+// The value is determined by a getter registered for the type T and
+// the pflag type of the flag (see RegisterFlagValueType). Getters for
+// the types of the pflag and pflags package are pre-registered.
+// Other value types are supported, if they are based on type T or provide
+// a Get method (like GetMap) without arguments returning type T.
+// Otherwise, a type mismatch is reported.
+func FlagValue[T any](fs *pflag.FlagSet, name string) (T, error) {
+	var _nil T
+
+	f := fs.Lookup(name)
+	if f == nil {
+		return _nil, fmt.Errorf("unknown flag %q", name)
+	}
+	if t := FlagAliasTarget(f); t != nil {
+		f = t
+	}
+
+	t := generics.TypeOf[T]()
+	if g := getter(t, f.Value.Type()); g != nil {
+		v, err := g(fs, f.Name)
+		if err != nil {
+			return _nil, err
+		}
+		return v.(T), nil
+	}
+
+	if v, ok := reflectValue(f.Value, t); ok {
+		return v.Interface().(T), nil
+	}
+	return _nil, fmt.Errorf("flag %q of type %s has no value of type %s", f.Name, f.Value.Type(), t)
+}
+
+func reflectValue(value pflag.Value, t reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if e := v.Elem(); e.Kind() == t.Kind() && e.Kind() != reflect.Struct && e.Type().ConvertibleTo(t) {
+			return e.Convert(t), true
+		}
+	}
+	for i := 0; i < v.NumMethod(); i++ {
+		m := v.Type().Method(i)
+		if !strings.HasPrefix(m.Name, "Get") || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || m.Type.Out(0) != t {
+			continue
+		}
+		return v.Method(i).Call(nil)[0], true
+	}
+	return reflect.Value{}, false
+}
+
+func init() {
+	RegisterFlagValueType("bool", (*pflag.FlagSet).GetBool)
+	RegisterFlagValueType("int", (*pflag.FlagSet).GetInt)
+	RegisterFlagValueType("count", (*pflag.FlagSet).GetCount)
+	RegisterFlagValueType("int8", (*pflag.FlagSet).GetInt8)
+	RegisterFlagValueType("int16", (*pflag.FlagSet).GetInt16)
+	RegisterFlagValueType("int32", (*pflag.FlagSet).GetInt32)
+	RegisterFlagValueType("int64", (*pflag.FlagSet).GetInt64)
+	RegisterFlagValueType("uint", (*pflag.FlagSet).GetUint)
+	RegisterFlagValueType("uint8", (*pflag.FlagSet).GetUint8)
+	RegisterFlagValueType("uint16", (*pflag.FlagSet).GetUint16)
+	RegisterFlagValueType("uint32", (*pflag.FlagSet).GetUint32)
+	RegisterFlagValueType("uint64", (*pflag.FlagSet).GetUint64)
+	RegisterFlagValueType("float32", (*pflag.FlagSet).GetFloat32)
+	RegisterFlagValueType("float64", (*pflag.FlagSet).GetFloat64)
+	RegisterFlagValueType("string", (*pflag.FlagSet).GetString)
+	RegisterFlagValueType("duration", (*pflag.FlagSet).GetDuration)
+	RegisterFlagValueType("stringSlice", (*pflag.FlagSet).GetStringSlice)
+	RegisterFlagValueType("stringArray", (*pflag.FlagSet).GetStringArray)
+	RegisterFlagValueType("intSlice", (*pflag.FlagSet).GetIntSlice)
+	RegisterFlagValueType("int32Slice", (*pflag.FlagSet).GetInt32Slice)
+	RegisterFlagValueType("int64Slice", (*pflag.FlagSet).GetInt64Slice)
+	RegisterFlagValueType("uintSlice", (*pflag.FlagSet).GetUintSlice)
+	RegisterFlagValueType("float32Slice", (*pflag.FlagSet).GetFloat32Slice)
+	RegisterFlagValueType("float64Slice", (*pflag.FlagSet).GetFloat64Slice)
+	RegisterFlagValueType("boolSlice", (*pflag.FlagSet).GetBoolSlice)
+	RegisterFlagValueType("durationSlice", (*pflag.FlagSet).GetDurationSlice)
+	RegisterFlagValueType("stringToString", (*pflag.FlagSet).GetStringToString)
+	RegisterFlagValueType("stringToInt", (*pflag.FlagSet).GetStringToInt)
+	RegisterFlagValueType("stringToInt64", (*pflag.FlagSet).GetStringToInt64)
+	RegisterFlagValueType("ip", (*pflag.FlagSet).GetIP)
+	RegisterFlagValueType("ipSlice", (*pflag.FlagSet).GetIPSlice)
+	RegisterFlagValueType("ipMask", (*pflag.FlagSet).GetIPv4Mask)
+	RegisterFlagValueType("ipNet", (*pflag.FlagSet).GetIPNet)
+	RegisterFlagValueType("bytesHex", (*pflag.FlagSet).GetBytesHex)
+	RegisterFlagValueType("bytesBase64", (*pflag.FlagSet).GetBytesBase64)
+
+	RegisterFlagValueType("*bool", pflags.GetBoolRef)
+	RegisterFlagValueType("*int", pflags.GetIntRef)
+	RegisterFlagValueType("*int8", pflags.GetInt8Ref)
+	RegisterFlagValueType("*int16", pflags.GetInt16Ref)
+	RegisterFlagValueType("*int32", pflags.GetInt32Ref)
+	RegisterFlagValueType("*int64", pflags.GetInt64Ref)
+	RegisterFlagValueType("*uint", pflags.GetUintRef)
+	RegisterFlagValueType("*uint8", pflags.GetUint8Ref)
+	RegisterFlagValueType("*uint16", pflags.GetUint16Ref)
+	RegisterFlagValueType("*uint32", pflags.GetUint32Ref)
+	RegisterFlagValueType("*uint64", pflags.GetUint64Ref)
+	RegisterFlagValueType("*float32", pflags.GetFloat32Ref)
+	RegisterFlagValueType("*float64", pflags.GetFloat64Ref)
+	RegisterFlagValueType("*string", pflags.GetStringRef)
+	RegisterFlagValueType("*Duration", pflags.GetDurationRef)
+	RegisterFlagValueType("!bytesBase64", pflags.GetBytes)
+	RegisterFlagValueType("LabeledString", pflags.GetLabeledStringValue)
+	RegisterFlagValueType("<name>=<YAML>", pflags.GetLabeledValue)
+	RegisterFlagValueType("{<name>=<value>}", pflags.GetIdentityPath)
+}
@@ -0,0 +1,65 @@
+package flagutils_test
+
+import (
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+
+	"github.com/mandelsoft/flagutils"
+	"github.com/mandelsoft/flagutils/parallel"
+	"github.com/mandelsoft/flagutils/pflags"
+)
+
+var _ = Describe("typed flag values", func() {
+	var fs *pflag.FlagSet
+
+	BeforeEach(func() {
+		fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.Duration("timeout", time.Second, "timeout")
+		fs.StringSlice("columns", nil, "columns")
+		fs.Int64("size", 0, "size")
+		var ref *int
+		pflags.IntRefVarP(fs, &ref, "limit", "", nil, "limit")
+		var path string
+		pflags.PathVarP(fs, &path, "dir", "", "", "directory")
+		var m map[string]interface{}
+		pflags.StringToValueVarP(fs, &m, "values", "", nil, "values")
+	})
+
+	It("provides core pflag values", func() {
+		MustBeSuccessful(fs.Parse([]string{"--timeout", "1m", "--columns", "a,b", "--size", "5"}))
+		Expect(flagutils.FlagValue[time.Duration](fs, "timeout")).To(Equal(time.Minute))
+		Expect(flagutils.FlagValue[[]string](fs, "columns")).To(Equal([]string{"a", "b"}))
+		Expect(flagutils.FlagValue[int64](fs, "size")).To(Equal(int64(5)))
+	})
+
+	It("provides pflags values", func() {
+		MustBeSuccessful(fs.Parse([]string{"--limit", "3", "--dir", "a/b", "--values", "a=[1]"}))
+		Expect(*Must(flagutils.FlagValue[*int](fs, "limit"))).To(Equal(3))
+		Expect(flagutils.FlagValue[string](fs, "dir")).To(Equal("a/b"))
+		Expect(flagutils.FlagValue[map[string]interface{}](fs, "values")).To(Equal(map[string]interface{}{"a": []interface{}{1.0}}))
+	})
+
+	It("reports type mismatches", func() {
+		_, err := flagutils.FlagValue[int](fs, "size")
+		Expect(err).To(MatchError(`flag "size" of type int64 has no value of type int`))
+		_, err = flagutils.FlagValue[string](fs, "timeout")
+		Expect(err).To(MatchError(`flag "timeout" of type duration has no value of type string`))
+		_, err = flagutils.FlagValue[string](fs, "unknown")
+		Expect(err).To(MatchError(`unknown flag "unknown"`))
+	})
+
+	It("provides values from option sets", func() {
+		set := flagutils.NewOptionSet(flagutils.NewFlagSetRecorder(), parallel.New().WithAliases("workers"))
+		set.AddFlags(fs)
+		MustBeSuccessful(fs.Parse([]string{"--workers", "4"}))
+		Expect(flagutils.Value[int](set, "parallel")).To(Equal(4))
+		Expect(flagutils.Value[int](set, "workers")).To(Equal(4))
+
+		_, err := flagutils.Value[int](flagutils.NewOptionSet(parallel.New()), "parallel")
+		Expect(err).To(MatchError("no flag set recorded for option set"))
+	})
+})